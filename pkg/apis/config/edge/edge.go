@@ -0,0 +1,91 @@
+// Package edge contains the keink specific fields of the node entries of a
+// kind config. kind decodes its config strictly, so these fields are split
+// off the raw config before kind parses it
+package edge
+
+import (
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// Node holds the keink specific fields of a node entry
+type Node struct {
+	// Runtime is the container runtime of an edge node, see constants.Runtimes
+	Runtime string `json:"runtime,omitempty"`
+	// NodeGroup is the NodeGroup an edge node belongs to
+	NodeGroup string `json:"nodeGroup,omitempty"`
+	// Resources limits the node container, like a small edge device. The
+	// Node reports the cpu and memory limits in its Allocatable, its
+	// Capacity stays the one of the host
+	Resources *Resources `json:"resources,omitempty"`
+}
+
+// Resources are the limits of an edge node container
+type Resources struct {
+	// CPUs is the number of CPUs, such as "0.5" or "2"
+	CPUs string `json:"cpus,omitempty"`
+	// Memory is the memory limit, a Kubernetes quantity such as "512Mi" or "1Gi"
+	Memory string `json:"memory,omitempty"`
+	// Pids is the maximum number of processes
+	Pids int64 `json:"pids,omitempty"`
+}
+
+// fields lists the json names of the Node fields
+var fields = []string{
+	"runtime",
+	"nodeGroup",
+	"resources",
+}
+
+// Split removes the keink specific fields from the node entries of the raw
+// config. It returns the config kind can parse, and the fields of every
+// node entry in the order of the entries
+func Split(raw []byte) ([]byte, []Node, error) {
+	cfg := map[string]interface{}{}
+	if err := yaml.Unmarshal(raw, &cfg); err != nil {
+		return nil, nil, errors.Wrap(err, "unable to decode config")
+	}
+
+	entries, ok := cfg["nodes"].([]interface{})
+	if !ok {
+		return raw, nil, nil
+	}
+
+	nodes := make([]Node, len(entries))
+	found := false
+	for i, entry := range entries {
+		fieldMap, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		own := map[string]interface{}{}
+		for _, field := range fields {
+			if value, ok := fieldMap[field]; ok {
+				own[field] = value
+				delete(fieldMap, field)
+				found = true
+			}
+		}
+		if len(own) == 0 {
+			continue
+		}
+		b, err := yaml.Marshal(own)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "unable to decode node %d", i)
+		}
+		if err := yaml.UnmarshalStrict(b, &nodes[i]); err != nil {
+			return nil, nil, errors.Wrapf(err, "unable to decode node %d", i)
+		}
+	}
+
+	// leave the config untouched when there is nothing to strip, so kind
+	// reports errors against what the user wrote
+	if !found {
+		return raw, nodes, nil
+	}
+	out, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to encode config")
+	}
+	return out, nodes, nil
+}
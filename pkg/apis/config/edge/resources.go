@@ -0,0 +1,88 @@
+package edge
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// memoryUnits are the suffixes of a memory size, those of the Kubernetes
+// quantities. The lowercase docker units are left out, m is milli in a
+// Kubernetes quantity, so 512m would mean half a byte there
+var memoryUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"Ki", 1 << 10},
+	{"Mi", 1 << 20},
+	{"Gi", 1 << 30},
+	{"Ti", 1 << 40},
+	{"k", 1000},
+	{"M", 1000 * 1000},
+	{"G", 1000 * 1000 * 1000},
+	{"T", 1000 * 1000 * 1000 * 1000},
+}
+
+// MilliCPUs returns the CPUs in thousandths, 0 when unset
+func (r *Resources) MilliCPUs() (int64, error) {
+	if r.CPUs == "" {
+		return 0, nil
+	}
+	cpus, err := strconv.ParseFloat(r.CPUs, 64)
+	if err != nil || cpus <= 0 {
+		return 0, fmt.Errorf("invalid cpus %q", r.CPUs)
+	}
+	return int64(cpus * 1000), nil
+}
+
+// MemoryBytes returns the memory limit in bytes, 0 when unset
+func (r *Resources) MemoryBytes() (int64, error) {
+	if r.Memory == "" {
+		return 0, nil
+	}
+	value, multiplier := r.Memory, int64(1)
+	for _, unit := range memoryUnits {
+		if strings.HasSuffix(r.Memory, unit.suffix) {
+			value, multiplier = strings.TrimSuffix(r.Memory, unit.suffix), unit.multiplier
+			break
+		}
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid memory %q, must be a number of bytes with one of the Kubernetes suffixes Ki, Mi, Gi, Ti, k, M, G or T", r.Memory)
+	}
+	return n * multiplier, nil
+}
+
+// Validate checks the limits can be parsed
+func (r *Resources) Validate() error {
+	if _, err := r.MilliCPUs(); err != nil {
+		return err
+	}
+	if _, err := r.MemoryBytes(); err != nil {
+		return err
+	}
+	if r.Pids < 0 {
+		return fmt.Errorf("invalid pids %d", r.Pids)
+	}
+	return nil
+}
+
+// DockerArgs returns the docker run and docker update flags of the limits,
+// swap is disabled so the memory limit is the whole memory of the node
+func (r *Resources) DockerArgs() ([]string, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	args := []string{}
+	if r.CPUs != "" {
+		args = append(args, "--cpus", r.CPUs)
+	}
+	if memory, _ := r.MemoryBytes(); memory > 0 {
+		args = append(args, "--memory", fmt.Sprintf("%db", memory), "--memory-swap", fmt.Sprintf("%db", memory))
+	}
+	if r.Pids > 0 {
+		args = append(args, "--pids-limit", fmt.Sprint(r.Pids))
+	}
+	return args, nil
+}
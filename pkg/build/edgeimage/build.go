@@ -13,10 +13,11 @@ import (
 func Build(options ...Option) error {
 	// default options
 	ctx := &buildContext{
-		image:     DefaultImage,
-		baseImage: DefaultBaseImage,
-		logger:    log.NoopLogger{},
-		arch:      runtime.GOARCH,
+		image:          DefaultImage,
+		baseImage:      DefaultBaseImage,
+		cloudCoreImage: DefaultCloudCoreImage,
+		logger:         log.NoopLogger{},
+		arch:           runtime.GOARCH,
 	}
 
 	// apply user options
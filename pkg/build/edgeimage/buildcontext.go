@@ -23,6 +23,10 @@ type buildContext struct {
 	logger       log.Logger
 	arch         string
 	kubeEdgeRoot string
+	runtimes     []string
+	// cloudCoreImage is built from the same cloudcore binary and
+	// preloaded into the node image, none is built when it is empty
+	cloudCoreImage string
 	// non-option fields
 	builder kube.Builder
 }
@@ -39,6 +43,14 @@ func (c *buildContext) Build() (err error) {
 	}
 	c.logger.V(0).Info("Finished building KubeEdge")
 
+	if c.cloudCoreImage != "" {
+		c.logger.V(0).Infof("Building cloudcore image %q ...", c.cloudCoreImage)
+		if err := c.buildCloudCoreImage(bits); err != nil {
+			c.logger.Errorf("Failed to build the cloudcore image: %v", err)
+			return err
+		}
+	}
+
 	// then the perform the actual docker image build
 	c.logger.V(0).Info("Building edge image ...")
 	return c.buildImage(bits)
@@ -112,6 +124,30 @@ func (c *buildContext) buildImage(bits kube.Bits) error {
 		}
 	}
 
+	// preload the cloudcore image for keadm init in container mode
+	if c.cloudCoreImage != "" {
+		if err := c.preloadCloudCoreImage(cmder); err != nil {
+			c.logger.Errorf("Image build Failed! Failed to preload the cloudcore image: %v", err)
+			return err
+		}
+	}
+
+	// install the extra container runtimes
+	for _, runtime := range c.runtimes {
+		script, err := runtimeInstallScript(runtime, c.arch)
+		if err != nil {
+			return err
+		}
+		if script == "" {
+			continue
+		}
+		c.logger.V(0).Infof("Installing container runtime %s ...", runtime)
+		if err := execInBuild("bash", "-c", script); err != nil {
+			c.logger.Errorf("Image build Failed! Failed to install runtime %s: %v", runtime, err)
+			return err
+		}
+	}
+
 	// Save the image changes to a new image
 	cmd := exec.Command(
 		"docker", "commit",
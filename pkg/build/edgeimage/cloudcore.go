@@ -0,0 +1,110 @@
+package edgeimage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/build/nodeimage/shared/kube"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+)
+
+// cloudCoreBaseImage is the base of the cloudcore image, the binary is built
+// on the host so it needs a glibc based image
+const cloudCoreBaseImage = "debian:bookworm-slim"
+
+const cloudCoreDockerfile = `FROM %s
+COPY cloudcore /usr/local/bin/cloudcore
+ENTRYPOINT ["/usr/local/bin/cloudcore"]
+`
+
+// buildCloudCoreImage builds the cloudcore container image from the
+// cloudcore binary of the build
+func (c *buildContext) buildCloudCoreImage(bits kube.Bits) error {
+	binary := ""
+	for _, p := range bits.BinaryPaths() {
+		if path.Base(p) == "cloudcore" {
+			binary = p
+		}
+	}
+	if binary == "" {
+		return errors.New("no cloudcore binary in the build")
+	}
+
+	dir, err := os.MkdirTemp("", "keink-cloudcore-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := copyFile(binary, filepath.Join(dir, "cloudcore")); err != nil {
+		return err
+	}
+	dockerfile := fmt.Sprintf(cloudCoreDockerfile, cloudCoreBaseImage)
+	if err := os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte(dockerfile), 0644); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("docker", "build", "--platform="+dockerBuildOsAndArch(c.arch), "-t", c.cloudCoreImage, dir)
+	exec.InheritOutput(cmd)
+	if err := cmd.Run(); err != nil {
+		return errors.Wrap(err, "failed to build the cloudcore image")
+	}
+	return nil
+}
+
+// preloadCloudCoreImage imports the cloudcore image into the containerd of
+// the build container, like kind preloads the Kubernetes images
+func (c *buildContext) preloadCloudCoreImage(cmder exec.Cmder) error {
+	if err := cmder.Command("bash", "-c", "nohup containerd > /dev/null 2>&1 &").Run(); err != nil {
+		return errors.Wrap(err, "failed to start containerd")
+	}
+	defer func() {
+		_ = cmder.Command("pkill", "containerd").Run()
+	}()
+	// give containerd a moment to create its socket
+	time.Sleep(2 * time.Second)
+
+	save := exec.Command("docker", "save", c.cloudCoreImage)
+	reader, writer := io.Pipe()
+	save.SetStdout(writer)
+	errCh := make(chan error, 1)
+	go func() {
+		err := save.Run()
+		writer.CloseWithError(err)
+		errCh <- err
+	}()
+
+	load := cmder.Command("ctr", "--namespace=k8s.io", "images", "import", "--label=io.cri-containerd.pinned=pinned", "--all-platforms", "--no-unpack", "--digests", "-")
+	if err := load.SetStdin(reader).Run(); err != nil {
+		return errors.Wrap(err, "failed to import the cloudcore image")
+	}
+	if err := <-errCh; err != nil {
+		return errors.Wrap(err, "failed to save the cloudcore image")
+	}
+
+	return cmder.Command("bash", "-c", fmt.Sprintf("echo %s > %s", c.cloudCoreImage, constants.CloudCoreImageFile)).Run()
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
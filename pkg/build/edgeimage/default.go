@@ -9,3 +9,7 @@ const DefaultImage = "kubeedge/node:latest"
 // we will add KubeEdge components based on this image
 // Just keep the same with kind default node image
 const DefaultBaseImage = defaults.Image
+
+// DefaultCloudCoreImage is the default name:tag for the cloudcore image
+// built along with the node image
+const DefaultCloudCoreImage = "kubeedge/cloudcore:keink"
@@ -80,18 +80,30 @@ func (b *dockerBuilder) Build() (kube.Bits, error) {
 		"cloudcore",
 		"edgecore",
 	}
+	// binaries only some features need, KubeEdge releases without their
+	// make target still build a node image, nodeGroup and --lite then
+	// fail when they are used
+	optional := []string{
+		// runs the NodeGroup and EdgeApplication controllers
+		"controllermanager",
+		// hollow edgecore of the lite edge nodes
+		"edgemark",
+	}
 
 	// build binaries
 	for _, component := range what {
-		cmd := exec.Command("make",
-			"all",
-			"WHAT="+component,
-		).SetEnv(env...)
-		exec.InheritOutput(cmd)
-		if err := cmd.Run(); err != nil {
+		if err := b.make(env, component); err != nil {
 			return nil, errors.Wrap(err, fmt.Sprintf("failed to build %s", component))
 		}
 	}
+	built := map[string]bool{}
+	for _, component := range optional {
+		if err := b.make(env, component); err != nil {
+			b.logger.Warnf("Skipping %s, it failed to build: %v", component, err)
+			continue
+		}
+		built[component] = true
+	}
 
 	binDir := filepath.Join(b.kubeEdgeRoot,
 		"_output", "local", "bin",
@@ -105,24 +117,47 @@ func (b *dockerBuilder) Build() (kube.Bits, error) {
 		"build", "tools",
 	)
 
+	binaryPaths := []string{
+		// binaries for kubeedge
+		filepath.Join(filepath.Join(binDir, "keadm")),
+		filepath.Join(filepath.Join(binDir, "cloudcore")),
+		filepath.Join(filepath.Join(binDir, "edgecore")),
+
+		// CRDs required by KubeEdge
+		filepath.Join(crdDir, "devices", "devices_v1beta1_device.yaml"),
+		filepath.Join(crdDir, "devices", "devices_v1beta1_devicemodel.yaml"),
+		filepath.Join(crdDir, "reliablesyncs", "cluster_objectsync_v1alpha1.yaml"),
+		filepath.Join(crdDir, "reliablesyncs", "objectsync_v1alpha1.yaml"),
+		filepath.Join(crdDir, "router", "router_v1_rule.yaml"),
+		filepath.Join(crdDir, "router", "router_v1_ruleEndpoint.yaml"),
+		filepath.Join(crdDir, "apps", "apps_v1alpha1_nodegroup.yaml"),
+		filepath.Join(crdDir, "apps", "apps_v1alpha1_edgeapplication.yaml"),
+
+		// cloudcore.service and edgecore.service
+		filepath.Join(serviceDir, "edgecore.service"),
+		filepath.Join(serviceDir, "cloudcore.service"),
+	}
+	if built["controllermanager"] {
+		binaryPaths = append(binaryPaths,
+			filepath.Join(binDir, "controllermanager"),
+			filepath.Join(serviceDir, "controllermanager.service"),
+		)
+	}
+	if built["edgemark"] {
+		binaryPaths = append(binaryPaths, filepath.Join(binDir, "edgemark"))
+	}
+
 	return &bits{
-		binaryPaths: []string{
-			// binaries for kubeedge
-			filepath.Join(filepath.Join(binDir, "keadm")),
-			filepath.Join(filepath.Join(binDir, "cloudcore")),
-			filepath.Join(filepath.Join(binDir, "edgecore")),
-
-			// CRDs required by KubeEdge
-			filepath.Join(crdDir, "devices", "devices_v1beta1_device.yaml"),
-			filepath.Join(crdDir, "devices", "devices_v1beta1_devicemodel.yaml"),
-			filepath.Join(crdDir, "reliablesyncs", "cluster_objectsync_v1alpha1.yaml"),
-			filepath.Join(crdDir, "reliablesyncs", "objectsync_v1alpha1.yaml"),
-			filepath.Join(crdDir, "router", "router_v1_rule.yaml"),
-			filepath.Join(crdDir, "router", "router_v1_ruleEndpoint.yaml"),
-
-			// cloudcore.service and edgecore.service
-			filepath.Join(serviceDir, "edgecore.service"),
-			filepath.Join(serviceDir, "cloudcore.service"),
-		},
+		binaryPaths: binaryPaths,
 	}, nil
 }
+
+// make builds the component of the KubeEdge source with its make target
+func (b *dockerBuilder) make(env []string, component string) error {
+	cmd := exec.Command("make",
+		"all",
+		"WHAT="+component,
+	).SetEnv(env...)
+	exec.InheritOutput(cmd)
+	return cmd.Run()
+}
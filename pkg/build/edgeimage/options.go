@@ -56,3 +56,26 @@ func WithArch(arch string) Option {
 		return nil
 	})
 }
+
+// WithRuntimes installs container runtimes besides containerd, so that edge
+// nodes can be configured to use them
+func WithRuntimes(runtimes ...string) Option {
+	return optionAdapter(func(b *buildContext) error {
+		for _, runtime := range runtimes {
+			if _, err := runtimeInstallScript(runtime, b.arch); err != nil {
+				return err
+			}
+		}
+		b.runtimes = append(b.runtimes, runtimes...)
+		return nil
+	})
+}
+
+// WithCloudCoreImage configures a build to also build the cloudcore image
+// `image` and preload it into the node image, an empty image skips it
+func WithCloudCoreImage(image string) Option {
+	return optionAdapter(func(b *buildContext) error {
+		b.cloudCoreImage = image
+		return nil
+	})
+}
@@ -0,0 +1,120 @@
+package edgeimage
+
+import (
+	"fmt"
+
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+)
+
+// the versions of the container runtimes installed besides containerd
+const (
+	crioVersion       = "v1.30"
+	dockerVersion     = "27.3.1"
+	criDockerdVersion = "0.3.15"
+)
+
+// installCRIO installs CRI-O from the upstream packages, it is left
+// disabled so that only the edge nodes configured with it start it
+const installCRIO = `set -e
+export DEBIAN_FRONTEND=noninteractive
+apt-get update
+apt-get install -y --no-install-recommends ca-certificates curl gnupg
+mkdir -p /etc/apt/keyrings
+curl -fsSL https://download.opensuse.org/repositories/isv:/cri-o:/stable:/%[1]s/deb/Release.key | gpg --dearmor -o /etc/apt/keyrings/cri-o-apt-keyring.gpg
+echo "deb [signed-by=/etc/apt/keyrings/cri-o-apt-keyring.gpg] https://download.opensuse.org/repositories/isv:/cri-o:/stable:/%[1]s/deb/ /" > /etc/apt/sources.list.d/cri-o.list
+apt-get update
+apt-get install -y --no-install-recommends cri-o
+# keep the CNI config of the node, not the CRI-O example bridge
+rm -f /etc/cni/net.d/*crio*
+systemctl disable crio || true
+rm -rf /var/lib/apt/lists/*
+`
+
+// installDocker installs the static docker binaries, using the containerd
+// of the node image, and cri-dockerd. Both are left disabled so that only
+// the edge nodes configured with docker start them
+const installDocker = `set -e
+export DEBIAN_FRONTEND=noninteractive
+apt-get update
+apt-get install -y --no-install-recommends ca-certificates curl
+rm -rf /var/lib/apt/lists/*
+curl -fsSL https://download.docker.com/linux/static/stable/%[2]s/docker-%[1]s.tgz | tar -xz -C /tmp
+install -m 0755 /tmp/docker/docker /tmp/docker/dockerd /tmp/docker/docker-init /tmp/docker/docker-proxy /usr/local/bin/
+rm -rf /tmp/docker
+curl -fsSL https://github.com/Mirantis/cri-dockerd/releases/download/v%[3]s/cri-dockerd-%[3]s.%[4]s.tgz | tar -xz -C /tmp
+install -m 0755 /tmp/cri-dockerd/cri-dockerd /usr/local/bin/
+rm -rf /tmp/cri-dockerd
+mkdir -p /etc/docker
+cat > /etc/docker/daemon.json <<'END'
+{
+  "exec-opts": ["native.cgroupdriver=systemd"],
+  "containerd": "/run/containerd/containerd.sock"
+}
+END
+cat > /etc/systemd/system/docker.service <<'END'
+[Unit]
+Description=Docker Application Container Engine
+After=network-online.target containerd.service
+Wants=containerd.service
+
+[Service]
+Type=notify
+ExecStart=/usr/local/bin/dockerd
+Delegate=yes
+KillMode=process
+LimitNOFILE=infinity
+
+[Install]
+WantedBy=multi-user.target
+END
+cat > /etc/systemd/system/cri-docker.socket <<'END'
+[Unit]
+Description=CRI Docker Socket for the API
+PartOf=cri-docker.service
+
+[Socket]
+ListenStream=%%t/cri-dockerd.sock
+SocketMode=0660
+SocketUser=root
+SocketGroup=root
+
+[Install]
+WantedBy=sockets.target
+END
+cat > /etc/systemd/system/cri-docker.service <<'END'
+[Unit]
+Description=CRI Interface for Docker Application Container Engine
+After=docker.service cri-docker.socket
+Requires=docker.service cri-docker.socket
+
+[Service]
+Type=notify
+ExecStart=/usr/local/bin/cri-dockerd --container-runtime-endpoint fd:// --network-plugin=cni --cni-conf-dir=/etc/cni/net.d --cni-bin-dir=/opt/cni/bin
+
+[Install]
+WantedBy=multi-user.target
+END
+`
+
+// runtimeInstallScript returns the script installing the container runtime
+// in the build container, containerd ships with the kind node image
+func runtimeInstallScript(runtime, arch string) (string, error) {
+	switch runtime {
+	case constants.RuntimeContainerd:
+		return "", nil
+	case constants.RuntimeCRIO:
+		return fmt.Sprintf(installCRIO, crioVersion), nil
+	case constants.RuntimeDocker:
+		// the docker static binaries are named after the uname machine
+		dockerArch := arch
+		switch arch {
+		case "amd64":
+			dockerArch = "x86_64"
+		case "arm64":
+			dockerArch = "aarch64"
+		}
+		return fmt.Sprintf(installDocker, dockerVersion, dockerArch, criDockerdVersion, arch), nil
+	default:
+		return "", fmt.Errorf("unknown runtime %q, must be one of %v", runtime, constants.Runtimes)
+	}
+}
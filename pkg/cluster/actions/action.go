@@ -0,0 +1,73 @@
+// Package actions defines the extension point for steps that run once the
+// KubeEdge cluster is up, such as installing add-ons, deploying device
+// mappers or seeding test workloads.
+//
+// An Action is passed to Provider.CreateKubeEdge with cluster.CreateWithActions,
+// the actions run in the order they were given after cloudcore and edgecore
+// have started. If an action fails the cluster is deleted, unless it was
+// created with --retain.
+package actions
+
+import (
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	kindactions "sigs.k8s.io/kind/pkg/cluster/shared/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/shared/providers/docker"
+
+	"github.com/kubeedge/keink/pkg/cluster/internal/create/actions/kubeedge"
+)
+
+// Action is a step that runs against a freshly created KubeEdge cluster
+type Action interface {
+	Execute(ctx *ActionContext) error
+}
+
+// ActionFunc adapts a plain function to an Action
+type ActionFunc func(ctx *ActionContext) error
+
+// Execute runs the function
+func (f ActionFunc) Execute(ctx *ActionContext) error {
+	return f(ctx)
+}
+
+// ActionContext is the context passed to every Action, it embeds the kind
+// action context so the logger, the status, the cluster config and
+// Nodes() are available as well
+type ActionContext struct {
+	*kindactions.ActionContext
+}
+
+// NewActionContext returns a new ActionContext wrapping the kind one
+func NewActionContext(ctx *kindactions.ActionContext) *ActionContext {
+	return &ActionContext{
+		ActionContext: ctx,
+	}
+}
+
+// ControlPlane returns the control-plane node that runs cloudcore
+func (c *ActionContext) ControlPlane() (nodes.Node, error) {
+	allNodes, err := c.Nodes()
+	if err != nil {
+		return nil, err
+	}
+	return nodeutils.BootstrapControlPlaneNode(allNodes)
+}
+
+// EdgeNodes returns the nodes that run edgecore
+func (c *ActionContext) EdgeNodes() ([]nodes.Node, error) {
+	return docker.ListEdgeNodesByLabel(c.Config.Name)
+}
+
+// CloudCoreToken returns the token edge nodes use to join cloudcore
+func (c *ActionContext) CloudCoreToken() (string, error) {
+	if kubeedge.KubeEdgeToken != "" {
+		return kubeedge.KubeEdgeToken, nil
+	}
+
+	// the bootstrap ran in an earlier attempt, read it from the cluster
+	node, err := c.ControlPlane()
+	if err != nil {
+		return "", err
+	}
+	return kubeedge.ReadToken(node)
+}
@@ -0,0 +1,112 @@
+package cluster
+
+import (
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	shareddocker "sigs.k8s.io/kind/pkg/cluster/shared/providers/docker"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"github.com/kubeedge/keink/pkg/cluster/internal/cloudcore"
+	"github.com/kubeedge/keink/pkg/cluster/internal/create/actions/kubeedge"
+)
+
+// TestCloudCoreFailover kills the cloudcore most edge nodes of a highly
+// available cluster are connected to, and checks that every edge node
+// reconnects through the load balancer and is Ready within timeout
+func (p *Provider) TestCloudCoreFailover(name string, timeout time.Duration) error {
+	n, err := p.Provider.ListNodes(name)
+	if err != nil {
+		return fmt.Errorf("failed to list nodes of cluster %q: %v", name, err)
+	}
+	controlPlanes, err := nodeutils.ControlPlaneNodes(n)
+	if err != nil {
+		return err
+	}
+	edgeNodes, err := shareddocker.ListEdgeNodesByLabel(name)
+	if err != nil {
+		return fmt.Errorf("failed to list edge nodes of cluster %q: %v", name, err)
+	}
+	return cloudcore.Failover(p.Logger, controlPlanes, edgeNodes, timeout)
+}
+
+// CloudCoreToken returns the token edge nodes join the cluster with
+func (p *Provider) CloudCoreToken(name string) (string, error) {
+	controlPlane, err := p.controlPlane(name)
+	if err != nil {
+		return "", err
+	}
+	return kubeedge.ReadToken(controlPlane)
+}
+
+// RotateCloudCoreToken makes cloudcore issue a new token and returns it.
+// When updateEdgeNodes is set, every edge node gets the new token and
+// requests its certificate again with it
+func (p *Provider) RotateCloudCoreToken(name string, updateEdgeNodes bool) (string, error) {
+	n, err := p.Provider.ListNodes(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to list nodes of cluster %q: %v", name, err)
+	}
+	controlPlanes, err := nodeutils.ControlPlaneNodes(n)
+	if err != nil {
+		return "", err
+	}
+	token, err := cloudcore.RotateToken(p.Logger, controlPlanes, 5*time.Minute)
+	if err != nil {
+		return "", err
+	}
+	if !updateEdgeNodes {
+		return token, nil
+	}
+
+	edgeNodes, err := shareddocker.ListEdgeNodesByLabel(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to list edge nodes of cluster %q: %v", name, err)
+	}
+	fns := []func() error{}
+	for _, node := range edgeNodes {
+		node := node // capture loop variable
+		fns = append(fns, func() error {
+			return cloudcore.UpdateEdgeNode(controlPlanes[0], node, token, 5*time.Minute)
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// RotateEdgeCert replaces the certificate of the edge node with one valid
+// for validity, signed by the cloudcore CA, and waits for the node to be
+// Ready with it. edgecore renews it from cloudcore before it expires
+func (p *Provider) RotateEdgeCert(name, nodeName string, validity time.Duration) error {
+	node, controlPlane, err := p.edgeNodeAndControlPlane(name, nodeName)
+	if err != nil {
+		return err
+	}
+	return cloudcore.RotateEdgeCert(controlPlane, node, validity, 5*time.Minute)
+}
+
+// ExpireEdgeCert replaces the certificate of the edge node with an expired
+// one signed by the cloudcore CA, and restarts edgecore with it
+func (p *Provider) ExpireEdgeCert(name, nodeName string) error {
+	node, controlPlane, err := p.edgeNodeAndControlPlane(name, nodeName)
+	if err != nil {
+		return err
+	}
+	return cloudcore.IssueEdgeCert(controlPlane, node, 0)
+}
+
+func (p *Provider) edgeNodeAndControlPlane(name, nodeName string) (nodes.Node, nodes.Node, error) {
+	node, err := p.edgeNode(name, nodeName)
+	if err != nil {
+		return nil, nil, err
+	}
+	controlPlane, err := p.controlPlane(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return node, controlPlane, nil
+}
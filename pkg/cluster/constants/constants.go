@@ -4,3 +4,195 @@ package constants
 const (
 	EdgeNodeRoleValue string = "edge-node"
 )
+
+// CloudCoreImageFile records the cloudcore image preloaded in the node image
+// by `keink build edge-image`, keadm init uses it instead of pulling cloudcore
+const CloudCoreImageFile string = "/etc/kubeedge/cloudcore-image"
+
+// The cloudcore CA files on the control-plane node, the cloudHub tlsCAFile
+// and tlsCAKeyFile defaults of `cloudcore --defaultconfig`. cloudcore uses the
+// CA from them when they exist, instead of generating one
+const (
+	CloudCoreCAFile    string = "/etc/kubeedge/ca/rootCA.crt"
+	CloudCoreCAKeyFile string = "/etc/kubeedge/ca/rootCA.key"
+)
+
+// ExposedAddressFile records on the control-plane node the host address the
+// cloudcore ports are exposed at, so that a resumed cluster keeps it
+const ExposedAddressFile string = "/etc/kubeedge/keink/exposed-address"
+
+// CloudCoreNodePorts are the NodePorts of the cloudcore Service in container
+// mode, by cloudcore port. keadm init is given them explicitly so that the
+// ports --expose-cloudcore maps before the Service exists are its NodePorts
+var CloudCoreNodePorts = map[int32]int32{
+	10000: 30000,
+	10001: 30001,
+	10002: 30002,
+	10003: 30003,
+	10004: 30004,
+}
+
+// The named phases of the KubeEdge bootstrap, they can be skipped with
+// --skip-phases and a retained cluster is resumed from the first one
+// that has not completed
+const (
+	// PhaseControlPlaneReady waits for the control-plane node to be Ready
+	PhaseControlPlaneReady string = "control-plane-ready"
+	// PhaseKubeProxy keeps kube-proxy away from the edge nodes
+	PhaseKubeProxy string = "kube-proxy"
+	// PhaseCloudCore installs and starts cloudcore on the control-plane
+	PhaseCloudCore string = "cloudcore"
+	// PhaseEdgeCore joins the edge nodes with edgecore
+	PhaseEdgeCore string = "edgecore"
+	// PhaseNodeGroups creates the NodeGroups of the edge nodes
+	PhaseNodeGroups string = "node-groups"
+	// PhaseEdgeCNI sets up pod networking on the edge nodes
+	PhaseEdgeCNI string = "edge-cni"
+)
+
+// KubeEdgePhases lists the bootstrap phases in the order they run
+var KubeEdgePhases = []string{
+	PhaseControlPlaneReady,
+	PhaseKubeProxy,
+	PhaseCloudCore,
+	PhaseEdgeCore,
+	PhaseNodeGroups,
+	PhaseEdgeCNI,
+}
+
+// The add-ons that can be installed with --addon
+const (
+	// AddonEdgeMesh installs EdgeMesh, so pods on edge nodes can reach Services
+	AddonEdgeMesh string = "edgemesh"
+	// AddonMapper installs the device mapper of a protocol, mapper=<protocol>
+	AddonMapper string = "mapper"
+)
+
+// Addons lists the add-ons that can be installed
+var Addons = []string{
+	AddonEdgeMesh,
+	AddonMapper,
+}
+
+// The CNI setups of the edge nodes that can be chosen with --edge-cni
+const (
+	// EdgeCNINone leaves the edge nodes with the CNI config of the kind worker
+	EdgeCNINone string = "none"
+	// EdgeCNIBridge writes a static host-local CNI config and routes
+	EdgeCNIBridge string = "bridge"
+	// EdgeCNIFlannelEdge runs flannel on the edge nodes through the metaServer
+	EdgeCNIFlannelEdge string = "flannel-edge"
+	// EdgeCNIKindnet runs kindnet on the edge nodes, without kube-proxy
+	EdgeCNIKindnet string = "kindnet"
+)
+
+// EdgeCNIs lists the CNI setups of the edge nodes
+var EdgeCNIs = []string{
+	EdgeCNINone,
+	EdgeCNIBridge,
+	EdgeCNIFlannelEdge,
+	EdgeCNIKindnet,
+}
+
+// The operations that simulate outages of an edge node with `keink edge`
+const (
+	// EdgeNodeStop stops edgecore, the node goes NotReady
+	EdgeNodeStop string = "stop"
+	// EdgeNodeStart starts edgecore again, the node goes Ready
+	EdgeNodeStart string = "start"
+	// EdgeNodePause freezes the node container like a power loss, the node goes NotReady
+	EdgeNodePause string = "pause"
+	// EdgeNodeUnpause resumes the node container, the node goes Ready
+	EdgeNodeUnpause string = "unpause"
+	// EdgeNodeCrash kills edgecore with SIGKILL, systemd restarts it and the node goes Ready
+	EdgeNodeCrash string = "crash"
+)
+
+// EdgeNodeOperations lists the operations on edge nodes
+var EdgeNodeOperations = []string{
+	EdgeNodeStop,
+	EdgeNodeStart,
+	EdgeNodePause,
+	EdgeNodeUnpause,
+	EdgeNodeCrash,
+}
+
+// The container runtimes an edge node can use, set with `runtime` on the
+// edge-node entries of the config
+const (
+	// RuntimeContainerd is the containerd of the kind node image, the default
+	RuntimeContainerd string = "containerd"
+	// RuntimeCRIO is CRI-O, the node image must be built with it
+	RuntimeCRIO string = "cri-o"
+	// RuntimeDocker is docker through cri-dockerd, the node image must be built with it
+	RuntimeDocker string = "docker"
+)
+
+// Runtimes lists the container runtimes of the edge nodes
+var Runtimes = []string{
+	RuntimeContainerd,
+	RuntimeCRIO,
+	RuntimeDocker,
+}
+
+// The protocols of the simulated devices, `keink create device --protocol`
+const (
+	// DeviceProtocolModbus is a Modbus TCP server
+	DeviceProtocolModbus string = "modbus"
+	// DeviceProtocolOPCUA is an OPC UA PLC simulator
+	DeviceProtocolOPCUA string = "opcua"
+	// DeviceProtocolMQTT is an MQTT broker with a publisher of the property values
+	DeviceProtocolMQTT string = "mqtt"
+)
+
+// DeviceProtocols lists the protocols of the simulated devices
+var DeviceProtocols = []string{
+	DeviceProtocolModbus,
+	DeviceProtocolOPCUA,
+	DeviceProtocolMQTT,
+}
+
+// The MQTT brokers of the edgecore eventBus, set with --mqtt-mode
+const (
+	// MQTTModeInternal is the broker built into edgecore, the default
+	MQTTModeInternal string = "internal"
+	// MQTTModeExternal is a mosquitto container shared by the edge nodes
+	MQTTModeExternal string = "external"
+	// MQTTModeBoth runs the built-in broker and uses the external one
+	MQTTModeBoth string = "both"
+)
+
+// MQTTModes lists the MQTT brokers of the edgecore eventBus
+var MQTTModes = []string{
+	MQTTModeInternal,
+	MQTTModeExternal,
+	MQTTModeBoth,
+}
+
+// ContainerClusterLabelKey labels the containers keink runs next to the
+// kind nodes, such as device simulators and the MQTT broker, with the name
+// of their cluster so they are deleted with it
+const ContainerClusterLabelKey = "io.kubeedge.keink.cluster"
+
+// The ways cloudcore runs, reported by Provider.KubeEdgeStatus
+const (
+	// CloudCoreModeSystemd is a systemd service on every control-plane node
+	CloudCoreModeSystemd string = "systemd"
+	// CloudCoreModeContainer is the cloudcore Deployment keadm installs, set
+	// with --container-mode
+	CloudCoreModeContainer string = "container"
+)
+
+// The output formats of `keink get edge-nodes` and `keink status`
+const (
+	// OutputTable is a table for humans, the default
+	OutputTable string = "table"
+	// OutputJSON is JSON for scripts
+	OutputJSON string = "json"
+)
+
+// Outputs lists the output formats
+var Outputs = []string{
+	OutputTable,
+	OutputJSON,
+}
@@ -1,10 +1,17 @@
 package cluster
 
 import (
+	"fmt"
+	"os"
 	"time"
 
+	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/shared/apis/config/encoding"
 
+	"github.com/kubeedge/keink/pkg/apis/config/edge"
+	"github.com/kubeedge/keink/pkg/cluster/actions"
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+	"github.com/kubeedge/keink/pkg/cluster/internal/cloudcore"
 	internalcreate "github.com/kubeedge/keink/pkg/cluster/internal/create"
 )
 
@@ -42,24 +49,65 @@ func CreateWithWaitForReady(waitTime time.Duration) CreateOption {
 	})
 }
 
+// CreateWithRetain sets the explicit --retain, the nodes are kept when the
+// creation fails, for debugging and for --resume
+func CreateWithRetain(retain bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.Retain = retain
+		return nil
+	})
+}
+
 // CreateWithConfigFile configures the config file path to use
 func CreateWithConfigFile(path string) CreateOption {
 	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
-		var err error
-		o.Config, err = encoding.Load(path)
-		return err
+		// special case: empty path -> default config
+		if path == "" {
+			var err error
+			o.Config, err = encoding.Load(path)
+			return err
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return errors.Wrap(err, "error reading file")
+		}
+		return parseConfig(o, raw)
 	})
 }
 
 // CreateWithRawConfig configures the config to use from raw (yaml) bytes
 func CreateWithRawConfig(raw []byte) CreateOption {
 	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
-		var err error
-		o.Config, err = encoding.Parse(raw)
-		return err
+		return parseConfig(o, raw)
 	})
 }
 
+// parseConfig splits the keink specific fields off the node entries, then
+// lets kind parse the rest of the config
+func parseConfig(o *internalcreate.ClusterOptions, raw []byte) error {
+	raw, nodes, err := edge.Split(raw)
+	if err != nil {
+		return err
+	}
+	for i, node := range nodes {
+		if node.Runtime != "" && !contains(constants.Runtimes, node.Runtime) {
+			return fmt.Errorf("unknown runtime %q of node %d, must be one of %v", node.Runtime, i, constants.Runtimes)
+		}
+		if node.Resources != nil {
+			if err := node.Resources.Validate(); err != nil {
+				return fmt.Errorf("invalid resources of node %d: %v", i, err)
+			}
+		}
+	}
+
+	o.Config, err = encoding.Parse(raw)
+	if err != nil {
+		return err
+	}
+	o.NodeSettings = nodes
+	return nil
+}
+
 // CreateWithAdvertiseAddress sets the explicit --advertise-address ip
 func CreateWithAdvertiseAddress(address string) CreateOption {
 	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
@@ -75,3 +123,132 @@ func CreateWithContainerMode(containerMode bool) CreateOption {
 		return nil
 	})
 }
+
+// CreateWithExposeCloudCore sets the explicit --expose-cloudcore, the
+// cloudcore ports of the control-plane are mapped to the host so that
+// machines outside the cluster can join it
+func CreateWithExposeCloudCore(expose bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.ExposeCloudCore = expose
+		return nil
+	})
+}
+
+// CreateWithCA sets the explicit --ca-cert and --ca-key, the PEM encoded CA
+// cloudcore issues its server certificate and the edge certificates with
+func CreateWithCA(certFile, keyFile string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		if certFile == "" && keyFile == "" {
+			return nil
+		}
+		if certFile == "" || keyFile == "" {
+			return errors.New("both the CA certificate and key must be given")
+		}
+		var err error
+		if o.CACert, err = os.ReadFile(certFile); err != nil {
+			return errors.Wrap(err, "error reading the CA certificate")
+		}
+		if o.CAKey, err = os.ReadFile(keyFile); err != nil {
+			return errors.Wrap(err, "error reading the CA key")
+		}
+		return cloudcore.ValidateCA(o.CACert, o.CAKey)
+	})
+}
+
+// CreateWithMQTTMode sets the explicit --mqtt-mode, the MQTT broker of the
+// edgecore eventBus, see constants.MQTTModes
+func CreateWithMQTTMode(mode string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		if mode != "" && !contains(constants.MQTTModes, mode) {
+			return fmt.Errorf("unknown MQTT mode %q, must be one of %v", mode, constants.MQTTModes)
+		}
+		o.MQTTMode = mode
+		return nil
+	})
+}
+
+// CreateWithRouter sets the explicit --enable-router, turning on the
+// cloudcore router and the edgecore serviceBus of the Rule API
+func CreateWithRouter(enable bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.EnableRouter = enable
+		return nil
+	})
+}
+
+// CreateWithResume sets the explicit --resume, the KubeEdge bootstrap of an
+// existing (retained) cluster continues from the first incomplete phase
+func CreateWithResume(resume bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.Resume = resume
+		return nil
+	})
+}
+
+// CreateWithSkipPhases sets the explicit --skip-phases
+func CreateWithSkipPhases(phases ...string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		for _, phase := range phases {
+			if !contains(constants.KubeEdgePhases, phase) {
+				return fmt.Errorf("unknown phase %q, must be one of %v", phase, constants.KubeEdgePhases)
+			}
+		}
+		o.SkipPhases = append(o.SkipPhases, phases...)
+		return nil
+	})
+}
+
+// CreateWithEdgeCNI sets the explicit --edge-cni
+func CreateWithEdgeCNI(cni string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		if cni != "" && !contains(constants.EdgeCNIs, cni) {
+			return fmt.Errorf("unknown edge CNI %q, must be one of %v", cni, constants.EdgeCNIs)
+		}
+		o.EdgeCNI = cni
+		return nil
+	})
+}
+
+// CreateWithStream sets the explicit --enable-stream
+func CreateWithStream(enable bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.EnableStream = enable
+		return nil
+	})
+}
+
+// CreateWithActions adds extension actions that run, in order, once
+// cloudcore and edgecore are up, see the actions package
+func CreateWithActions(a ...actions.Action) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.Actions = append(o.Actions, a...)
+		return nil
+	})
+}
+
+// CreateWithAddons sets the explicit --addon, add-ons are installed after
+// the KubeEdge bootstrap and before the extension actions
+func CreateWithAddons(addons ...string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		for _, addon := range addons {
+			name, value := internalcreate.ParseAddon(addon)
+			if !contains(constants.Addons, name) {
+				return fmt.Errorf("unknown addon %q, must be one of %v", name, constants.Addons)
+			}
+			if name == constants.AddonMapper && !contains(constants.DeviceProtocols, value) {
+				return fmt.Errorf("addon %q needs a protocol, %s=<protocol> with one of %v", name, name, constants.DeviceProtocols)
+			}
+		}
+		o.Addons = append(o.Addons, addons...)
+		return nil
+	})
+}
+
+func contains(list []string, name string) bool {
+	for _, item := range list {
+		if item == name {
+			return true
+		}
+	}
+	return false
+}
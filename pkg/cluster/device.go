@@ -0,0 +1,52 @@
+package cluster
+
+import (
+	"fmt"
+
+	internalcreate "github.com/kubeedge/keink/pkg/cluster/internal/create"
+	"github.com/kubeedge/keink/pkg/cluster/internal/device"
+)
+
+// DeviceOptions are the options of a simulated device
+type DeviceOptions struct {
+	// Name of the Device, defaults to <node>-<protocol>
+	Name string
+	// Protocol is one of constants.DeviceProtocols
+	Protocol string
+	// ModelFile is a DeviceModel manifest, a model with a single INT
+	// property is created when it is empty
+	ModelFile string
+}
+
+// CreateDevice runs a simulator of the device protocol on the kind network
+// and creates a DeviceModel and a Device bound to the edge node nodeName
+func (p *Provider) CreateDevice(name, nodeName string, opts DeviceOptions) error {
+	node, controlPlane, err := p.edgeNodeAndControlPlane(name, nodeName)
+	if err != nil {
+		return err
+	}
+	if opts.Name == "" {
+		opts.Name = fmt.Sprintf("%s-%s", nodeName, opts.Protocol)
+	}
+	return device.Create(p.Logger, name, controlPlane, node, device.Options{
+		Name:      opts.Name,
+		Protocol:  opts.Protocol,
+		ModelFile: opts.ModelFile,
+	})
+}
+
+// DeleteDevices removes the simulated device deviceName, or all of them
+// when it is empty, with their Device objects
+func (p *Provider) DeleteDevices(name, deviceName string) error {
+	// the Device objects are gone with the cluster, only remove the
+	// simulators then
+	controlPlane, _ := p.controlPlane(name)
+	return device.Delete(p.Logger, name, controlPlane, deviceName)
+}
+
+// DeleteContainers removes the containers keink runs next to the kind
+// nodes of the cluster, the device simulators and the MQTT broker, or of
+// every cluster when name is empty
+func (p *Provider) DeleteContainers(name string) error {
+	return internalcreate.DeleteContainers(name)
+}
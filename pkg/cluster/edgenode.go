@@ -0,0 +1,35 @@
+package cluster
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	shareddocker "sigs.k8s.io/kind/pkg/cluster/shared/providers/docker"
+)
+
+// edgeNode returns the edge node named nodeName of the cluster
+func (p *Provider) edgeNode(name, nodeName string) (nodes.Node, error) {
+	edgeNodes, err := shareddocker.ListEdgeNodesByLabel(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list edge nodes of cluster %q: %v", name, err)
+	}
+	for _, node := range edgeNodes {
+		if node.String() == nodeName {
+			return node, nil
+		}
+	}
+	return nil, fmt.Errorf("edge node %q not found in cluster %q", nodeName, name)
+}
+
+// controlPlane returns the bootstrap control-plane node of the cluster
+func (p *Provider) controlPlane(name string) (nodes.Node, error) {
+	n, err := p.Provider.ListNodes(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes of cluster %q: %v", name, err)
+	}
+	if len(n) == 0 {
+		return nil, fmt.Errorf("no nodes found for cluster %q", name)
+	}
+	return nodeutils.BootstrapControlPlaneNode(n)
+}
@@ -0,0 +1,61 @@
+package cluster
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+	"github.com/kubeedge/keink/pkg/cluster/internal/edgenode"
+)
+
+// OperateEdgeNode runs one of constants.EdgeNodeOperations on the edge node
+// and waits up to wait for the node to become NotReady or Ready accordingly.
+// A zero wait returns as soon as the operation has run
+func (p *Provider) OperateEdgeNode(name, nodeName, operation string, wait time.Duration) error {
+	node, err := p.edgeNode(name, nodeName)
+	if err != nil {
+		return err
+	}
+
+	// the node goes Ready again after the operations that bring edgecore back
+	ready := true
+	switch operation {
+	case constants.EdgeNodeStop:
+		ready = false
+		err = edgenode.Stop(node)
+	case constants.EdgeNodeStart:
+		err = edgenode.Start(node)
+	case constants.EdgeNodePause:
+		ready = false
+		err = edgenode.Pause(node)
+	case constants.EdgeNodeUnpause:
+		err = edgenode.Unpause(node)
+	case constants.EdgeNodeCrash:
+		// systemd restarts edgecore before the node is marked NotReady
+		err = edgenode.Crash(node, time.Minute)
+	default:
+		return fmt.Errorf("unknown edge node operation %q, must be one of %v", operation, constants.EdgeNodeOperations)
+	}
+	if err != nil {
+		return err
+	}
+	if wait <= 0 {
+		return nil
+	}
+
+	controlPlane, err := p.controlPlane(name)
+	if err != nil {
+		return err
+	}
+	return edgenode.WaitForReady(controlPlane, nodeName, ready, wait)
+}
+
+// WaitForEdgeNode waits up to timeout for the edge node to be Ready, or
+// NotReady when ready is false
+func (p *Provider) WaitForEdgeNode(name, nodeName string, ready bool, timeout time.Duration) error {
+	controlPlane, err := p.controlPlane(name)
+	if err != nil {
+		return err
+	}
+	return edgenode.WaitForReady(controlPlane, nodeName, ready, timeout)
+}
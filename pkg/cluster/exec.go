@@ -0,0 +1,34 @@
+package cluster
+
+import (
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// ExecOnEdgeNode runs the command in the edge node container and returns
+// its combined output lines
+func (p *Provider) ExecOnEdgeNode(name, nodeName, command string, args ...string) ([]string, error) {
+	node, err := p.edgeNode(name, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := exec.CombinedOutputLines(node.Command(command, args...))
+	if err != nil {
+		return lines, errors.Wrapf(err, "failed to run %s on %s", command, nodeName)
+	}
+	return lines, nil
+}
+
+// Kubectl runs kubectl with the args on the bootstrap control-plane node of
+// the cluster and returns its combined output lines
+func (p *Provider) Kubectl(name string, args ...string) ([]string, error) {
+	controlPlane, err := p.controlPlane(name)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := exec.CombinedOutputLines(controlPlane.Command("kubectl", args...))
+	if err != nil {
+		return lines, errors.Wrap(err, "kubectl failed")
+	}
+	return lines, nil
+}
@@ -0,0 +1,162 @@
+package cluster
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/shared/apis/config"
+
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+	internalcreate "github.com/kubeedge/keink/pkg/cluster/internal/create"
+)
+
+// exposedCloudCorePorts are the cloudhub websocket and https ports, and the
+// stream and tunnel ports, which are mapped to the host
+var exposedCloudCorePorts = []int32{10000, 10002, 10003, 10004}
+
+// cloudCoreRouterPort is the port of the cloudcore router, which serves the
+// rest endpoints of the Rules
+const cloudCoreRouterPort int32 = 9443
+
+// exposeCloudCore maps the cloudcore ports of the bootstrap control-plane
+// node to the same ports on all the host addresses
+func exposeCloudCore(opts *internalcreate.ClusterOptions) error {
+	if opts.ExposedAddress == "" {
+		ip, err := detectHostIP()
+		if err != nil {
+			return err
+		}
+		opts.ExposedAddress = ip
+	}
+
+	for i := range opts.Config.Nodes {
+		node := &opts.Config.Nodes[i]
+		if node.Role != config.ControlPlaneRole {
+			continue
+		}
+		ports := exposedCloudCorePorts
+		// the keadm chart has no NodePort for the router
+		if opts.EnableRouter && !opts.ContainerMode {
+			ports = append(ports, cloudCoreRouterPort)
+		}
+		for _, port := range ports {
+			containerPort := port
+			if opts.ContainerMode {
+				containerPort = constants.CloudCoreNodePorts[port]
+			}
+			node.ExtraPortMappings = append(node.ExtraPortMappings, config.PortMapping{
+				ContainerPort: containerPort,
+				HostPort:      port,
+				ListenAddress: "0.0.0.0",
+				Protocol:      config.PortMappingProtocolTCP,
+			})
+		}
+		// kind names the first control-plane entry the bootstrap node
+		return nil
+	}
+	return fmt.Errorf("no control-plane node to expose cloudcore on")
+}
+
+// detectHostIP returns the address other machines on the network reach the host
+// at, the address of the interface of its default route, or of the first
+// interface that is up when the host has no default route
+func detectHostIP() (string, error) {
+	candidates := []string{}
+	if name, err := defaultRouteInterface(); err == nil {
+		candidates = append(candidates, name)
+	}
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return "", fmt.Errorf("failed to detect the host IP: %v", err)
+	}
+	for _, iface := range interfaces {
+		// the bridges of docker are only reachable from the host itself
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 ||
+			strings.HasPrefix(iface.Name, "docker") || strings.HasPrefix(iface.Name, "br-") || strings.HasPrefix(iface.Name, "veth") {
+			continue
+		}
+		candidates = append(candidates, iface.Name)
+	}
+
+	for _, name := range candidates {
+		iface, err := net.InterfaceByName(name)
+		if err != nil {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+				return ipNet.IP.String(), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("failed to detect the host IP: no interface has an IPv4 address")
+}
+
+// exposedAddress returns the address the cloudcore ports were exposed at,
+// as recorded on the bootstrap control-plane node, or an empty string
+func exposedAddress(node nodes.Node) (string, error) {
+	cmd := node.Command("bash", "-c", fmt.Sprintf("cat %s 2>/dev/null || true", constants.ExposedAddressFile))
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the exposed cloudcore address: %v", err)
+	}
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return strings.TrimSpace(lines[0]), nil
+}
+
+// defaultRouteInterface returns the interface of the IPv4 default route,
+// from the routing table of Linux
+func defaultRouteInterface() (string, error) {
+	data, err := os.ReadFile("/proc/net/route")
+	if err != nil {
+		return "", err
+	}
+	// Iface Destination Gateway ..., the default route has destination 0
+	for _, line := range strings.Split(string(data), "\n")[1:] {
+		fields := strings.Fields(line)
+		if len(fields) > 1 && fields[1] == "00000000" {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("no default route")
+}
+
+// JoinCommand returns the `keadm join` command a machine outside the cluster,
+// such as a Raspberry Pi or a VM, runs to join the cluster as an edge node.
+// The cluster must have been created with the cloudcore ports exposed, hostIP
+// defaults to the address recorded when they were exposed, and is detected
+// for clusters that have none
+func (p *Provider) JoinCommand(name, hostIP string) (string, error) {
+	if hostIP == "" {
+		controlPlane, err := p.controlPlane(name)
+		if err != nil {
+			return "", err
+		}
+		if hostIP, err = exposedAddress(controlPlane); err != nil {
+			return "", err
+		}
+	}
+	if hostIP == "" {
+		ip, err := detectHostIP()
+		if err != nil {
+			return "", err
+		}
+		hostIP = ip
+	}
+
+	token, err := p.CloudCoreToken(name)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("keadm join --cloudcore-ipport=%s:%d --token=%s", hostIP, exposedCloudCorePorts[0], token), nil
+}
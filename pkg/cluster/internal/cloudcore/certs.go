@@ -0,0 +1,216 @@
+package cloudcore
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+	"github.com/kubeedge/keink/pkg/cluster/internal/edgenode"
+)
+
+// IssueEdgeCert signs a certificate for the edge node with the cloudcore CA,
+// valid for validity from now, or already expired when validity isn't
+// positive, and restarts edgecore with it. edgecore renews the certificate
+// from cloudcore before it expires, so a short validity exercises the
+// rotation in minutes
+func IssueEdgeCert(controlPlane, node nodes.Node, validity time.Duration) error {
+	ca, caKey, err := readCA(controlPlane)
+	if err != nil {
+		return err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+	notBefore := time.Now().Add(-time.Minute)
+	notAfter := time.Now().Add(validity)
+	if validity <= 0 {
+		notBefore = time.Now().Add(-2 * time.Hour)
+		notAfter = time.Now().Add(-time.Hour)
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:   node.String(),
+			Organization: []string{"KubeEdge"},
+		},
+		NotBefore:   notBefore,
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to sign the edge certificate")
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	files := map[string][]byte{
+		edgeCertFiles[0]: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		edgeCertFiles[1]: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+	for path, content := range files {
+		if err := nodeutils.WriteFile(node, path, string(content)); err != nil {
+			return errors.Wrapf(err, "failed to write %s on %s", path, node)
+		}
+	}
+	if err := node.Command("systemctl", "restart", "edgecore").Run(); err != nil {
+		return errors.Wrapf(err, "failed to restart edgecore on %s", node)
+	}
+	return nil
+}
+
+// RotateEdgeCert issues a certificate valid for validity to the edge node
+// and waits for the node to be Ready with it
+func RotateEdgeCert(controlPlane, node nodes.Node, validity, timeout time.Duration) error {
+	if validity <= 0 {
+		return errors.New("the validity of the certificate must be positive")
+	}
+	if err := IssueEdgeCert(controlPlane, node, validity); err != nil {
+		return err
+	}
+	return edgenode.WaitForReady(controlPlane, node.String(), true, timeout)
+}
+
+// readCA reads the CA cloudcore signs the edge certificates with, from the
+// casecret secret cloudcore stores it in, or from its CA files
+func readCA(controlPlane nodes.Node) (*x509.Certificate, crypto.Signer, error) {
+	certDER, keyDER, err := readCASecret(controlPlane)
+	if err != nil {
+		certPEM, err := exec.Output(controlPlane.Command("cat", constants.CloudCoreCAFile))
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to read the cloudcore CA")
+		}
+		keyPEM, err := exec.Output(controlPlane.Command("cat", constants.CloudCoreCAKeyFile))
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to read the cloudcore CA key")
+		}
+		if certDER, err = decodePEM(certPEM); err != nil {
+			return nil, nil, err
+		}
+		if keyDER, err = decodePEM(keyPEM); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	ca, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse the cloudcore CA")
+	}
+	key, err := parsePrivateKey(keyDER)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse the cloudcore CA key")
+	}
+	return ca, key, nil
+}
+
+// ValidateCA checks a PEM encoded CA given to keink before it is installed:
+// cloudcore loads the CA key as an EC key in SEC1 encoding, and the key must
+// be the one of the certificate, otherwise cloudcore crash loops
+func ValidateCA(certPEM, keyPEM []byte) error {
+	certDER, err := decodePEM(certPEM)
+	if err != nil {
+		return errors.Wrap(err, "invalid CA certificate")
+	}
+	ca, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		return errors.Wrap(err, "invalid CA certificate")
+	}
+	if !ca.IsCA {
+		return errors.New("the CA certificate is not a CA")
+	}
+
+	keyDER, err := decodePEM(keyPEM)
+	if err != nil {
+		return errors.Wrap(err, "invalid CA key")
+	}
+	key, err := x509.ParseECPrivateKey(keyDER)
+	if err != nil {
+		if _, err := parsePrivateKey(keyDER); err == nil {
+			return errors.New("cloudcore only loads an ECDSA CA key in SEC1 encoding (EC PRIVATE KEY), convert an ECDSA PKCS#8 key with `openssl ec`, or create an ECDSA CA")
+		}
+		return errors.Wrap(err, "invalid CA key")
+	}
+	if !key.PublicKey.Equal(ca.PublicKey) {
+		return errors.New("the CA key does not match the CA certificate")
+	}
+	return nil
+}
+
+// readCASecret reads the DER encoded CA and key of the casecret secret
+func readCASecret(controlPlane nodes.Node) ([]byte, []byte, error) {
+	read := func(key string) ([]byte, error) {
+		cmd := controlPlane.Command("kubectl", "get", "secret", "casecret", "-n", "kubeedge", "-o=jsonpath={.data."+key+"}")
+		out, err := exec.Output(cmd)
+		if err != nil {
+			return nil, err
+		}
+		data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(out)))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) == 0 {
+			return nil, errors.Errorf("no %s in casecret", key)
+		}
+		return data, nil
+	}
+	cert, err := read("cadata")
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := read("cakeydata")
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+func decodePEM(data []byte) ([]byte, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data")
+	}
+	return block.Bytes, nil
+}
+
+// parsePrivateKey parses a DER encoded RSA or ECDSA key, in any of the
+// encodings cloudcore and openssl write
+func parsePrivateKey(der []byte) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported key type")
+	}
+	return signer, nil
+}
@@ -0,0 +1,123 @@
+// Package cloudcore operates the cloudcore instances of a running cluster
+package cloudcore
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"github.com/kubeedge/keink/pkg/cluster/internal/edgenode"
+)
+
+// cloudHubPort is the cloudhub websocket port, every connected edge node
+// holds one connection to it
+const cloudHubPort = 10000
+
+// Failover kills the cloudcore most edge nodes are connected to, then waits
+// for every edge node to reconnect to another cloudcore and be Ready. The
+// killed cloudcore is started again before returning
+func Failover(logger log.Logger, controlPlanes []nodes.Node, edgeNodes []nodes.Node, timeout time.Duration) error {
+	if len(controlPlanes) < 2 {
+		return errors.New("cloudcore failover needs several control-plane nodes")
+	}
+	if len(edgeNodes) == 0 {
+		return errors.New("cloudcore failover needs at least one edge node")
+	}
+
+	var active nodes.Node
+	most := 0
+	for _, node := range controlPlanes {
+		count, err := connections(node)
+		if err != nil {
+			return err
+		}
+		logger.V(1).Infof("cloudcore on %s has %d edge connections", node, count)
+		if count > most {
+			active, most = node, count
+		}
+	}
+	if active == nil {
+		return errors.New("no edge node is connected to cloudcore")
+	}
+
+	// stop after the kill, so that systemd doesn't restart it
+	logger.V(0).Infof("Killing the active cloudcore on %s", active)
+	cmd := active.Command("bash", "-c", "systemctl kill --signal=SIGKILL cloudcore && systemctl stop cloudcore")
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "failed to kill cloudcore on %s", active)
+	}
+	defer func() {
+		if err := active.Command("systemctl", "start", "cloudcore").Run(); err != nil {
+			logger.Warnf("failed to start cloudcore on %s again: %v", active, err)
+		}
+	}()
+
+	survivors := []nodes.Node{}
+	for _, node := range controlPlanes {
+		if node.String() != active.String() {
+			survivors = append(survivors, node)
+		}
+	}
+
+	start := time.Now()
+	for {
+		total := 0
+		for _, node := range survivors {
+			count, err := connections(node)
+			if err != nil {
+				return err
+			}
+			total += count
+		}
+		if total >= len(edgeNodes) {
+			break
+		}
+		if time.Since(start) > timeout {
+			return fmt.Errorf("only %d of %d edge nodes reconnected after %s", total, len(edgeNodes), timeout)
+		}
+		time.Sleep(2 * time.Second)
+	}
+
+	for _, node := range edgeNodes {
+		if err := edgenode.WaitForReady(survivors[0], node.String(), true, timeout-time.Since(start)); err != nil {
+			return err
+		}
+	}
+	logger.V(0).Infof("All %d edge nodes reconnected in %s", len(edgeNodes), time.Since(start).Round(time.Second))
+	return nil
+}
+
+// connections returns the number of established connections to the
+// cloudhub port of the cloudcore on the node
+func connections(node nodes.Node) (int, error) {
+	return countConnections(node, "")
+}
+
+// podConnections returns the number of edge nodes connected to the
+// cloudcore pod running on the node, keadm does not run it on the host
+// network, so they are counted in the network namespace of the container
+func podConnections(node nodes.Node, pod string) (int, error) {
+	nsenter := fmt.Sprintf(`pid=$(crictl inspect --output go-template --template '{{.info.pid}}' `+
+		`$(crictl ps -q --name cloudcore --pod $(crictl pods -q --name %s --state ready))) && nsenter -t "$pid" -n `, pod)
+	return countConnections(node, nsenter)
+}
+
+// countConnections counts the established cloudhub connections with ss,
+// run after prefix on the node
+func countConnections(node nodes.Node, prefix string) (int, error) {
+	cmd := node.Command("bash", "-c", fmt.Sprintf("%sss -Htn state established '( sport = :%d )' | wc -l", prefix, cloudHubPort))
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to count the cloudcore connections on %s", node)
+	}
+	if len(lines) == 0 {
+		return 0, nil
+	}
+	return strconv.Atoi(strings.TrimSpace(lines[0]))
+}
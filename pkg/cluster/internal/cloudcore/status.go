@@ -0,0 +1,152 @@
+package cloudcore
+
+import (
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+)
+
+// configFile is the cloudcore config of the systemd service
+const configFile = "/etc/kubeedge/config/cloudcore.yaml"
+
+// Status is the state of the cloudcore instances of a cluster
+type Status struct {
+	// Mode is one of constants.CloudCoreModeSystemd and
+	// constants.CloudCoreModeContainer
+	Mode string
+	// Version is the version of cloudcore, the image tag in container mode
+	Version string
+	// AdvertiseAddresses are the addresses cloudcore issues its server
+	// certificate for and edge nodes connect to
+	AdvertiseAddresses []string
+	// Instances are the cloudcore services, or pods in container mode
+	Instances []Instance
+}
+
+// Instance is a cloudcore service or pod
+type Instance struct {
+	// Node is the control-plane node it runs on
+	Node string
+	// State is the systemd ActiveState, or the pod phase
+	State string
+	// Connections is the number of edge nodes connected to it
+	Connections int
+}
+
+// config is the part of the cloudcore config keink reads
+type config struct {
+	Modules struct {
+		CloudHub struct {
+			AdvertiseAddress []string `json:"advertiseAddress"`
+		} `json:"cloudHub"`
+	} `json:"modules"`
+}
+
+// GetStatus returns the state of cloudcore on the control-plane nodes
+func GetStatus(controlPlanes []nodes.Node) (*Status, error) {
+	if len(controlPlanes) == 0 {
+		return nil, errors.New("no control-plane node")
+	}
+	if err := controlPlanes[0].Command("kubectl", "get", "deployment", "cloudcore", "-n", "kubeedge").Run(); err == nil {
+		return containerStatus(controlPlanes)
+	}
+	return systemdStatus(controlPlanes)
+}
+
+// systemdStatus returns the state of the cloudcore services
+func systemdStatus(controlPlanes []nodes.Node) (*Status, error) {
+	status := &Status{Mode: constants.CloudCoreModeSystemd}
+	for _, node := range controlPlanes {
+		lines, err := exec.OutputLines(node.Command("systemctl", "show", "--property=ActiveState", "--value", "cloudcore"))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the state of cloudcore on %s", node)
+		}
+		instance := Instance{Node: node.String(), State: firstLine(lines)}
+		if instance.Connections, err = connections(node); err != nil {
+			return nil, err
+		}
+		status.Instances = append(status.Instances, instance)
+	}
+
+	// every control-plane node runs the same binary and config
+	controlPlane := controlPlanes[0]
+	lines, err := exec.OutputLines(controlPlane.Command("cloudcore", "--version"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the cloudcore version")
+	}
+	status.Version = strings.TrimPrefix(firstLine(lines), "KubeEdge ")
+
+	raw, err := exec.Output(controlPlane.Command("cat", configFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the cloudcore config")
+	}
+	if status.AdvertiseAddresses, err = advertiseAddresses(raw); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// containerStatus returns the state of the cloudcore pods
+func containerStatus(controlPlanes []nodes.Node) (*Status, error) {
+	status := &Status{Mode: constants.CloudCoreModeContainer}
+	controlPlane := controlPlanes[0]
+
+	lines, err := exec.OutputLines(controlPlane.Command("kubectl", "get", "deployment", "cloudcore", "-n", "kubeedge",
+		"-o=jsonpath={.spec.template.spec.containers[0].image}"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the cloudcore image")
+	}
+	image := firstLine(lines)
+	status.Version = image[strings.LastIndex(image, ":")+1:]
+
+	lines, err = exec.OutputLines(controlPlane.Command("kubectl", "get", "pods", "-n", "kubeedge", "-l", "kubeedge=cloudcore",
+		`-o=jsonpath={range .items[*]}{.metadata.name} {.spec.nodeName} {.status.phase}{"\n"}{end}`))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the cloudcore pods")
+	}
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		instance := Instance{Node: fields[1], State: fields[2]}
+		for _, node := range controlPlanes {
+			if node.String() == instance.Node && instance.State == "Running" {
+				if instance.Connections, err = podConnections(node, fields[0]); err != nil {
+					return nil, err
+				}
+			}
+		}
+		status.Instances = append(status.Instances, instance)
+	}
+
+	raw, err := exec.Output(controlPlane.Command("kubectl", "get", "configmap", "cloudcore", "-n", "kubeedge",
+		`-o=jsonpath={.data.cloudcore\.yaml}`))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the cloudcore config")
+	}
+	if status.AdvertiseAddresses, err = advertiseAddresses(raw); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func advertiseAddresses(raw []byte) ([]string, error) {
+	c := &config{}
+	if err := yaml.Unmarshal(raw, c); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the cloudcore config")
+	}
+	return c.Modules.CloudHub.AdvertiseAddress, nil
+}
+
+func firstLine(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(lines[0])
+}
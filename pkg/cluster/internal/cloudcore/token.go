@@ -0,0 +1,89 @@
+package cloudcore
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"github.com/kubeedge/keink/pkg/cluster/internal/create/actions/kubeedge"
+	"github.com/kubeedge/keink/pkg/cluster/internal/edgenode"
+)
+
+// edgeCertFiles are the certificate edgecore requested from cloudcore with
+// the token, edgecore requests a new one when they are missing
+var edgeCertFiles = []string{
+	"/etc/kubeedge/certs/server.crt",
+	"/etc/kubeedge/certs/server.key",
+}
+
+// RotateToken makes cloudcore issue a new token, by deleting the tokensecret
+// and restarting cloudcore, and returns the new token once it is stored
+func RotateToken(logger log.Logger, controlPlanes []nodes.Node, timeout time.Duration) (string, error) {
+	if len(controlPlanes) == 0 {
+		return "", errors.New("no control-plane node")
+	}
+	controlPlane := controlPlanes[0]
+
+	old, err := kubeedge.ReadToken(controlPlane)
+	if err != nil {
+		return "", err
+	}
+
+	cmd := controlPlane.Command("kubectl", "delete", "secret", "tokensecret", "-n", "kubeedge", "--ignore-not-found")
+	lines, err := exec.CombinedOutputLines(cmd)
+	logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to delete the tokensecret")
+	}
+
+	if err := restart(logger, controlPlanes); err != nil {
+		return "", err
+	}
+
+	for start := time.Now(); time.Since(start) < timeout; time.Sleep(2 * time.Second) {
+		token, err := kubeedge.ReadToken(controlPlane)
+		if err == nil && token != old {
+			return token, nil
+		}
+	}
+	return "", fmt.Errorf("cloudcore did not issue a new token after %s", timeout)
+}
+
+// restart restarts cloudcore, which is a Deployment in container mode and
+// a systemd unit on every control-plane node otherwise
+func restart(logger log.Logger, controlPlanes []nodes.Node) error {
+	controlPlane := controlPlanes[0]
+	if err := controlPlane.Command("kubectl", "get", "deployment", "cloudcore", "-n", "kubeedge").Run(); err == nil {
+		cmd := controlPlane.Command("bash", "-c", "kubectl rollout restart deployment/cloudcore -n kubeedge && kubectl rollout status deployment/cloudcore -n kubeedge --timeout=300s")
+		lines, err := exec.CombinedOutputLines(cmd)
+		logger.V(3).Info(strings.Join(lines, "\n"))
+		if err != nil {
+			return errors.Wrap(err, "failed to restart cloudcore")
+		}
+		return nil
+	}
+
+	for _, node := range controlPlanes {
+		if err := node.Command("systemctl", "restart", "cloudcore").Run(); err != nil {
+			return errors.Wrapf(err, "failed to restart cloudcore on %s", node)
+		}
+	}
+	return nil
+}
+
+// UpdateEdgeNode writes the token to the edgecore config and removes the
+// edge certificate, so that edgecore requests a new one with the token when
+// it is restarted, then waits for the node to be Ready
+func UpdateEdgeNode(controlPlane, node nodes.Node, token string, timeout time.Duration) error {
+	script := fmt.Sprintf(`sed -i -e "s|token: .*|token: %s|g" /etc/kubeedge/config/edgecore.yaml && rm -f %s && systemctl restart edgecore`,
+		token, strings.Join(edgeCertFiles, " "))
+	if err := node.Command("bash", "-c", script).Run(); err != nil {
+		return errors.Wrapf(err, "failed to update the token of %s", node)
+	}
+	return edgenode.WaitForReady(controlPlane, node.String(), true, timeout)
+}
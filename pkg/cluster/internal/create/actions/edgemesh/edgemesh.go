@@ -0,0 +1,140 @@
+// Package edgemesh implements the action installing the EdgeMesh add-on
+package edgemesh
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"github.com/kubeedge/keink/pkg/cluster/actions"
+	"github.com/kubeedge/keink/pkg/cluster/internal/manifest"
+)
+
+// Action installs EdgeMesh so that pods on edge nodes can reach Services,
+// it needs the edgecore metaServer, the cloudcore dynamicController and
+// the stream tunnel to be enabled
+type Action struct{}
+
+// NewAction returns a new action for installing EdgeMesh
+func NewAction() actions.Action {
+	return &Action{}
+}
+
+// Execute runs the action
+func (a *Action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Installing EdgeMesh 🕸️")
+	defer ctx.Status.End(false)
+
+	controlPlane, err := ctx.ControlPlane()
+	if err != nil {
+		return err
+	}
+
+	// the kubernetes Service is served by kube-proxy on cloud nodes,
+	// keep EdgeMesh from proxying it
+	cmd := controlPlane.Command("kubectl", "label", "services", "kubernetes", "service.edgemesh.kubeedge.io/service-proxy-name=", "--overwrite")
+	lines, err := exec.CombinedOutputLines(cmd)
+	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		return errors.Wrap(err, "failed to label the kubernetes service")
+	}
+
+	psk, err := generatePSK()
+	if err != nil {
+		return err
+	}
+	relayIP, _, err := controlPlane.IP()
+	if err != nil {
+		return errors.Wrap(err, "failed to get control-plane IP")
+	}
+
+	if err := manifest.RenderAndApply(ctx.Logger, controlPlane, agentManifest, map[string]string{
+		"Image":            agentImage,
+		"PSK":              psk,
+		"RelayNodeName":    controlPlane.String(),
+		"RelayNodeAddress": relayIP,
+	}); err != nil {
+		return errors.Wrap(err, "failed to deploy edgemesh-agent")
+	}
+
+	cmd = controlPlane.Command("kubectl", "rollout", "status", "daemonset/edgemesh-agent", "-n", "kubeedge", "--timeout=300s")
+	lines, err = exec.CombinedOutputLines(cmd)
+	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		return errors.Wrap(err, "edgemesh-agent is not ready")
+	}
+
+	if err := a.check(ctx, controlPlane); err != nil {
+		return err
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// check verifies that a Service backed by a pod on one edge node can be
+// reached from another edge node
+func (a *Action) check(ctx *actions.ActionContext, controlPlane nodes.Node) error {
+	edgeNodes, err := ctx.EdgeNodes()
+	if err != nil {
+		return err
+	}
+	if len(edgeNodes) < 2 {
+		ctx.Logger.V(0).Info("Skipping the EdgeMesh check, it needs at least two edge nodes")
+		return nil
+	}
+
+	if err := manifest.RenderAndApply(ctx.Logger, controlPlane, checkManifest, map[string]string{
+		"ServerNode": edgeNodes[0].String(),
+	}); err != nil {
+		return errors.Wrap(err, "failed to deploy the EdgeMesh check server")
+	}
+	defer func() {
+		cleanup := controlPlane.Command("kubectl", "delete", "-n", "default", "--ignore-not-found",
+			"pod/edgemesh-check-server", "pod/edgemesh-check-client", "service/edgemesh-check")
+		_ = cleanup.Run()
+	}()
+
+	cmd := controlPlane.Command("kubectl", "wait", "--for=condition=Ready", "pod/edgemesh-check-server", "-n", "default", "--timeout=300s")
+	lines, err := exec.CombinedOutputLines(cmd)
+	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		return errors.Wrap(err, "EdgeMesh check server is not ready")
+	}
+
+	cmd = controlPlane.Command("kubectl", "get", "service", "edgemesh-check", "-n", "default", "-o=jsonpath={.spec.clusterIP}")
+	serviceIP, err := exec.Output(cmd)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the EdgeMesh check service IP")
+	}
+
+	if err := manifest.RenderAndApply(ctx.Logger, controlPlane, checkClientManifest, map[string]string{
+		"ClientNode": edgeNodes[1].String(),
+		"ServiceIP":  strings.TrimSpace(string(serviceIP)),
+	}); err != nil {
+		return errors.Wrap(err, "failed to deploy the EdgeMesh check client")
+	}
+
+	cmd = controlPlane.Command("kubectl", "wait", "--for=jsonpath={.status.phase}=Succeeded", "pod/edgemesh-check-client", "-n", "default", "--timeout=300s")
+	lines, err = exec.CombinedOutputLines(cmd)
+	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("service on %s is not reachable from %s through EdgeMesh", edgeNodes[0], edgeNodes[1]))
+	}
+	return nil
+}
+
+// generatePSK returns a random pre-shared key for the EdgeMesh tunnel
+func generatePSK() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "failed to generate EdgeMesh PSK")
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
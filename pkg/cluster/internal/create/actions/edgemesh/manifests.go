@@ -0,0 +1,170 @@
+package edgemesh
+
+// agentImage is the EdgeMesh agent image, since EdgeMesh v1.12 the agent
+// also runs the relay that edgemesh-server used to provide
+const agentImage = "kubeedge/edgemesh-agent:v1.14.0"
+
+// agentManifest deploys the EdgeMesh agent to every node, the relay node
+// is the control-plane, see https://edgemesh.netlify.app/guide/
+const agentManifest = `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: edgemesh-agent
+  namespace: kubeedge
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: edgemesh-agent
+rules:
+- apiGroups: [""]
+  resources: ["pods", "services", "endpoints", "nodes", "namespaces", "configmaps", "secrets"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: [""]
+  resources: ["events"]
+  verbs: ["create", "patch", "update"]
+- apiGroups: ["discovery.k8s.io"]
+  resources: ["endpointslices"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: ["networking.istio.io"]
+  resources: ["*"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: ["coordination.k8s.io"]
+  resources: ["leases"]
+  verbs: ["get", "create", "update"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: edgemesh-agent
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: edgemesh-agent
+subjects:
+- kind: ServiceAccount
+  name: edgemesh-agent
+  namespace: kubeedge
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: edgemesh-agent-cfg
+  namespace: kubeedge
+data:
+  edgemesh-agent.yaml: |
+    modules:
+      edgeDNS:
+        enable: true
+      edgeProxy:
+        enable: true
+      edgeTunnel:
+        enable: true
+        psk: {{ .PSK }}
+        relayNodes:
+        - nodeName: {{ .RelayNodeName }}
+          advertiseAddress:
+          - {{ .RelayNodeAddress }}
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: edgemesh-agent
+  namespace: kubeedge
+  labels:
+    k8s-app: kubeedge
+    kubeedge: edgemesh-agent
+spec:
+  selector:
+    matchLabels:
+      k8s-app: kubeedge
+      kubeedge: edgemesh-agent
+  template:
+    metadata:
+      labels:
+        k8s-app: kubeedge
+        kubeedge: edgemesh-agent
+    spec:
+      hostNetwork: true
+      serviceAccountName: edgemesh-agent
+      tolerations:
+      - operator: Exists
+      containers:
+      - name: edgemesh-agent
+        image: {{ .Image }}
+        securityContext:
+          privileged: true
+        env:
+        - name: MY_NODE_NAME
+          valueFrom:
+            fieldRef:
+              fieldPath: spec.nodeName
+        resources:
+          limits:
+            cpu: 1
+            memory: 256Mi
+          requests:
+            cpu: 100m
+            memory: 64Mi
+        volumeMounts:
+        - name: conf
+          mountPath: /etc/edgemesh/config
+        - name: host-time
+          mountPath: /etc/localtime
+          readOnly: true
+      volumes:
+      - name: conf
+        configMap:
+          name: edgemesh-agent-cfg
+      - name: host-time
+        hostPath:
+          path: /etc/localtime
+`
+
+// checkManifest is a Service backed by a pod on one edge node, and a
+// client pod on another edge node that must reach it through EdgeMesh
+const checkManifest = `apiVersion: v1
+kind: Pod
+metadata:
+  name: edgemesh-check-server
+  namespace: default
+  labels:
+    app: edgemesh-check
+spec:
+  nodeName: {{ .ServerNode }}
+  containers:
+  - name: server
+    image: busybox:1.36
+    command: ["sh", "-c", "mkdir -p /www && echo ok > /www/index.html && httpd -f -p 8080 -h /www"]
+    ports:
+    - containerPort: 8080
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: edgemesh-check
+  namespace: default
+spec:
+  selector:
+    app: edgemesh-check
+  ports:
+  - name: http-0
+    port: 80
+    targetPort: 8080
+`
+
+// checkClientManifest fetches the Service from another edge node, it
+// retries for a while as EdgeMesh needs some time to learn the endpoints
+const checkClientManifest = `apiVersion: v1
+kind: Pod
+metadata:
+  name: edgemesh-check-client
+  namespace: default
+spec:
+  nodeName: {{ .ClientNode }}
+  restartPolicy: Never
+  containers:
+  - name: client
+    image: busybox:1.36
+    command: ["sh", "-c", "for i in $(seq 1 30); do wget -q -T 5 -O- http://{{ .ServiceIP }} && exit 0; sleep 5; done; exit 1"]
+`
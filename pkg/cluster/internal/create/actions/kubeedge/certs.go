@@ -0,0 +1,155 @@
+package kubeedge
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+)
+
+// the kubeadm generated CA of the cluster, the API server trusts
+// certificates signed by it when it connects to the stream server
+const (
+	kubeCAFile    = "/etc/kubernetes/pki/ca.crt"
+	kubeCAKeyFile = "/etc/kubernetes/pki/ca.key"
+)
+
+// the stream certificates, these paths are the cloudStream defaults of
+// `cloudcore --defaultconfig`
+const (
+	streamCAFile   = "/etc/kubeedge/ca/streamCA.crt"
+	streamCertFile = "/etc/kubeedge/certs/stream.crt"
+	streamKeyFile  = "/etc/kubeedge/certs/stream.key"
+)
+
+// streamCertValidity is how long the generated stream certificate is valid
+const streamCertValidity = 365 * 24 * time.Hour
+
+// generateStreamCerts issues the cloudStream server certificate from the
+// cluster CA, the same way `certgen.sh stream` from KubeEdge does
+func generateStreamCerts(node nodes.Node, ips []string) error {
+	caPEM, err := exec.Output(node.Command("cat", kubeCAFile))
+	if err != nil {
+		return errors.Wrap(err, "failed to read the cluster CA")
+	}
+	caKeyPEM, err := exec.Output(node.Command("cat", kubeCAKeyFile))
+	if err != nil {
+		return errors.Wrap(err, "failed to read the cluster CA key")
+	}
+	ca, caKey, err := parseCA(caPEM, caKeyPEM)
+	if err != nil {
+		return err
+	}
+
+	certPEM, keyPEM, err := newServerCert(ca, caKey, "kubeedge-stream", ips, streamCertValidity)
+	if err != nil {
+		return err
+	}
+
+	files := map[string][]byte{
+		streamCAFile:   caPEM,
+		streamCertFile: certPEM,
+		streamKeyFile:  keyPEM,
+	}
+	for path, content := range files {
+		if err := nodeutils.WriteFile(node, path, string(content)); err != nil {
+			return errors.Wrapf(err, "failed to write %s", path)
+		}
+	}
+	return nil
+}
+
+// parseCA parses a PEM encoded CA certificate and its RSA key
+func parseCA(certPEM, keyPEM []byte) (*x509.Certificate, *rsa.PrivateKey, error) {
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, nil, errors.New("no PEM data found in the CA certificate")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse the CA certificate")
+	}
+
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, nil, errors.New("no PEM data found in the CA key")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes); err == nil {
+		return cert, key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse the CA key")
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, nil, errors.New("only RSA CA keys are supported")
+	}
+	return cert, key, nil
+}
+
+// newServerCert returns a PEM encoded certificate and RSA key signed by
+// the CA, valid for the given IP addresses
+func newServerCert(ca *x509.Certificate, caKey *rsa.PrivateKey, commonName string, ips []string, validity time.Duration) (certPEM, keyPEM []byte, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate key")
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 62))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate serial number")
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:   commonName,
+			Organization: []string{"KubeEdge"},
+		},
+		NotBefore:   now.Add(-5 * time.Minute),
+		NotAfter:    now.Add(validity),
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	for _, ip := range ips {
+		if parsed := net.ParseIP(ip); parsed != nil {
+			template.IPAddresses = append(template.IPAddresses, parsed)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to sign certificate")
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return certPEM, keyPEM, nil
+}
+
+// installCA writes the custom CA to the cloudcore CA files of the node
+func (a *Action) installCA(node nodes.Node) error {
+	files := map[string][]byte{
+		constants.CloudCoreCAFile:    a.CACert,
+		constants.CloudCoreCAKeyFile: a.CAKey,
+	}
+	for path, content := range files {
+		if err := nodeutils.WriteFile(node, path, string(content)); err != nil {
+			return errors.Wrapf(err, "failed to write %s", path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package kubeedge
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/cluster/shared/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/shared/providers/docker"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+	"github.com/kubeedge/keink/pkg/cluster/internal/manifest"
+)
+
+// edgeCNIConfigFile is where keink writes the CNI config of an edge node,
+// it sorts before the 10-kindnet.conflist left from the kind worker
+const edgeCNIConfigFile = "/etc/cni/net.d/05-keink.conflist"
+
+// kindnet can't reach the API server from edge nodes, where kube-proxy
+// doesn't run, so keep it on the cloud nodes
+var kindnetNotScheduleOnEdgeNode string = `kubectl patch daemonset kindnet -n kube-system -p '{"spec": {"template": {"spec": {"affinity": {"nodeAffinity": {"requiredDuringSchedulingIgnoredDuringExecution": {"nodeSelectorTerms": [{"matchExpressions": [{"key": "node-role.kubernetes.io/edge", "operator": "DoesNotExist"}]}]}}}}}}}'`
+
+// podNetwork is the pod network of a registered node
+type podNetwork struct {
+	podCIDR string
+	ip      string
+}
+
+// setupEdgeCNI gives the edge nodes a working pod network, the edge nodes
+// must have registered so that they have been assigned a pod CIDR
+func (a *Action) setupEdgeCNI(ctx *actions.ActionContext) error {
+	if a.EdgeCNI == "" || a.EdgeCNI == constants.EdgeCNINone {
+		return nil
+	}
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	controlPlane, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+	edgeNodes, err := docker.ListEdgeNodesByLabel(ctx.Config.Name)
+	if err != nil {
+		return err
+	}
+	if len(edgeNodes) == 0 {
+		return nil
+	}
+
+	// with kindnet the edge nodes get their own DaemonSet, see setupKindnetEdge
+	cmd := controlPlane.Command("bash", "-c", kindnetNotScheduleOnEdgeNode)
+	lines, err := exec.CombinedOutputLines(cmd)
+	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to stop kindnet scheduled to edge nodes %v ", lines))
+	}
+
+	networks, err := podNetworks(controlPlane, edgeNodes)
+	if err != nil {
+		return err
+	}
+
+	switch a.EdgeCNI {
+	case constants.EdgeCNIBridge:
+		return a.setupBridgeCNI(ctx, edgeNodes, networks)
+	case constants.EdgeCNIKindnet:
+		return a.setupKindnetEdge(ctx, controlPlane)
+	case constants.EdgeCNIFlannelEdge:
+		return a.setupFlannelEdge(ctx, controlPlane, edgeNodes, networks)
+	default:
+		return fmt.Errorf("unknown edge CNI %q, must be one of %v", a.EdgeCNI, constants.EdgeCNIs)
+	}
+}
+
+// setupBridgeCNI writes a static host-local CNI config on every edge node,
+// and the routes to the pod CIDRs of the other nodes. The kind node image
+// only ships the ptp, host-local, portmap and loopback plugins, so the
+// config uses ptp, like kindnet does, rather than the bridge plugin
+func (a *Action) setupBridgeCNI(ctx *actions.ActionContext, edgeNodes []nodes.Node, networks map[string]podNetwork) error {
+	fns := []func() error{}
+	for _, node := range edgeNodes {
+		node := node // capture loop variable
+		fns = append(fns, func() error {
+			network, ok := networks[node.String()]
+			if !ok {
+				return fmt.Errorf("edge node %s has no pod CIDR", node)
+			}
+			if err := nodeutils.WriteFile(node, edgeCNIConfigFile, fmt.Sprintf(bridgeCNIConfig, network.podCIDR)); err != nil {
+				return errors.Wrapf(err, "failed to write the CNI config of %s", node)
+			}
+			if err := a.masqueradePodTraffic(ctx, node, network.podCIDR); err != nil {
+				return err
+			}
+			return addPodRoutes(ctx, node, networks, nil)
+		})
+	}
+	return errors.UntilErrorConcurrent(fns)
+}
+
+// setupKindnetEdge runs a second kindnet DaemonSet on the edge nodes, which
+// talks to the API server directly instead of through the Service IP
+func (a *Action) setupKindnetEdge(ctx *actions.ActionContext, controlPlane nodes.Node) error {
+	cmd := controlPlane.Command("kubectl", "get", "daemonset", "kindnet", "-n", "kube-system", "-o=jsonpath={.spec.template.spec.containers[0].image}")
+	image, err := exec.Output(cmd)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the kindnet image")
+	}
+	endpoint, err := ctx.Provider.GetAPIServerInternalEndpoint(ctx.Config.Name)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the API server endpoint")
+	}
+	host, port, _ := strings.Cut(endpoint, ":")
+
+	if err := manifest.RenderAndApply(ctx.Logger, controlPlane, kindnetEdgeManifest, map[string]string{
+		"Image":                strings.TrimSpace(string(image)),
+		"PodSubnet":            ctx.Config.Networking.PodSubnet,
+		"ControlPlaneEndpoint": endpoint,
+		"APIServerHost":        host,
+		"APIServerPort":        port,
+	}); err != nil {
+		return errors.Wrap(err, "failed to deploy kindnet-edge")
+	}
+	return waitDaemonSet(ctx, controlPlane, "kindnet-edge")
+}
+
+// setupFlannelEdge runs flannel on the edge nodes through the edgecore
+// metaServer. flannel only routes to nodes it manages, so the routes to the
+// pod CIDRs of the cloud nodes, which run kindnet, are added by keink
+func (a *Action) setupFlannelEdge(ctx *actions.ActionContext, controlPlane nodes.Node, edgeNodes []nodes.Node, networks map[string]podNetwork) error {
+	if err := manifest.RenderAndApply(ctx.Logger, controlPlane, flannelEdgeManifest, map[string]string{
+		"PodSubnet": ctx.Config.Networking.PodSubnet,
+	}); err != nil {
+		return errors.Wrap(err, "failed to deploy kube-flannel-edge")
+	}
+	if err := waitDaemonSet(ctx, controlPlane, "kube-flannel-edge"); err != nil {
+		return err
+	}
+
+	edge := make(map[string]bool, len(edgeNodes))
+	for _, node := range edgeNodes {
+		edge[node.String()] = true
+	}
+	fns := []func() error{}
+	for _, node := range edgeNodes {
+		node := node // capture loop variable
+		fns = append(fns, func() error {
+			return addPodRoutes(ctx, node, networks, edge)
+		})
+	}
+	return errors.UntilErrorConcurrent(fns)
+}
+
+// masqueradePodTraffic masquerades traffic from the pods of the node that
+// leaves the cluster pod subnet, like the kindnet ip-masq rules
+func (a *Action) masqueradePodTraffic(ctx *actions.ActionContext, node nodes.Node, podCIDR string) error {
+	rule := fmt.Sprintf("POSTROUTING -s %s ! -d %s -j MASQUERADE -m comment --comment keink-edge-cni", podCIDR, ctx.Config.Networking.PodSubnet)
+	cmd := node.Command("bash", "-c", fmt.Sprintf("iptables -t nat -C %[1]s 2>/dev/null || iptables -t nat -A %[1]s", rule))
+	lines, err := exec.CombinedOutputLines(cmd)
+	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		return errors.Wrapf(err, "failed to masquerade pod traffic on %s", node)
+	}
+	return nil
+}
+
+// addPodRoutes routes the pod CIDR of every other node through that node,
+// skipping the nodes in except
+func addPodRoutes(ctx *actions.ActionContext, node nodes.Node, networks map[string]podNetwork, except map[string]bool) error {
+	for name, network := range networks {
+		if name == node.String() || except[name] {
+			continue
+		}
+		cmd := node.Command("ip", "route", "replace", network.podCIDR, "via", network.ip)
+		lines, err := exec.CombinedOutputLines(cmd)
+		ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+		if err != nil {
+			return errors.Wrapf(err, "failed to add the route to %s on %s", network.podCIDR, node)
+		}
+	}
+	return nil
+}
+
+// podNetworks returns the pod CIDR and internal IP of every node, waiting
+// for the edge nodes to be assigned a pod CIDR after they registered
+func podNetworks(controlPlane nodes.Node, edgeNodes []nodes.Node) (map[string]podNetwork, error) {
+	var networks map[string]podNetwork
+	for i := 0; i < 30; i++ {
+		cmd := controlPlane.Command("kubectl", "get", "nodes", `-o=jsonpath={range .items[*]}{.metadata.name} {.spec.podCIDR} {.status.addresses[?(@.type=="InternalIP")].address}{"\n"}{end}`)
+		lines, err := exec.OutputLines(cmd)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the node pod CIDRs")
+		}
+
+		networks = make(map[string]podNetwork, len(lines))
+		for _, line := range lines {
+			fields := strings.Fields(line)
+			if len(fields) != 3 {
+				continue
+			}
+			networks[fields[0]] = podNetwork{podCIDR: fields[1], ip: fields[2]}
+		}
+
+		assigned := true
+		for _, node := range edgeNodes {
+			if _, ok := networks[node.String()]; !ok {
+				assigned = false
+			}
+		}
+		if assigned {
+			return networks, nil
+		}
+		time.Sleep(2 * time.Second)
+	}
+	return networks, errors.New("edge nodes were not assigned a pod CIDR")
+}
+
+// waitDaemonSet waits for a kube-system DaemonSet to be rolled out
+func waitDaemonSet(ctx *actions.ActionContext, controlPlane nodes.Node, name string) error {
+	cmd := controlPlane.Command("kubectl", "rollout", "status", "daemonset/"+name, "-n", "kube-system", "--timeout=300s")
+	lines, err := exec.CombinedOutputLines(cmd)
+	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		return errors.Wrapf(err, "daemonset %s is not ready", name)
+	}
+	return nil
+}
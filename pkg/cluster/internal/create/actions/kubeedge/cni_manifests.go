@@ -0,0 +1,267 @@
+package kubeedge
+
+// bridgeCNIConfig is the static CNI config of an edge node, formatted with
+// the pod CIDR of the node
+const bridgeCNIConfig = `{
+  "cniVersion": "0.3.1",
+  "name": "keink",
+  "plugins": [
+    {
+      "type": "ptp",
+      "ipMasq": false,
+      "ipam": {
+        "type": "host-local",
+        "dataDir": "/run/cni-ipam-state",
+        "routes": [{"dst": "0.0.0.0/0"}],
+        "ranges": [[{"subnet": "%s"}]]
+      },
+      "mtu": 1500
+    },
+    {
+      "type": "portmap",
+      "capabilities": {"portMappings": true}
+    }
+  ]
+}
+`
+
+// kindnetEdgeManifest is the kind kindnet DaemonSet for edge nodes, it
+// reaches the API server directly because there is no kube-proxy on edge
+const kindnetEdgeManifest = `apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: kindnet-edge
+  namespace: kube-system
+  labels:
+    tier: node
+    app: kindnet-edge
+    k8s-app: kindnet
+spec:
+  selector:
+    matchLabels:
+      app: kindnet-edge
+  template:
+    metadata:
+      labels:
+        tier: node
+        app: kindnet-edge
+        k8s-app: kindnet
+    spec:
+      hostNetwork: true
+      nodeSelector:
+        kubernetes.io/os: linux
+        node-role.kubernetes.io/edge: ""
+      tolerations:
+      - operator: Exists
+      serviceAccountName: kindnet
+      containers:
+      - name: kindnet-cni
+        image: {{ .Image }}
+        env:
+        - name: HOST_IP
+          valueFrom:
+            fieldRef:
+              fieldPath: status.hostIP
+        - name: POD_IP
+          valueFrom:
+            fieldRef:
+              fieldPath: status.podIP
+        - name: POD_SUBNET
+          value: {{ .PodSubnet }}
+        - name: CONTROL_PLANE_ENDPOINT
+          value: {{ .ControlPlaneEndpoint }}
+        - name: KUBERNETES_SERVICE_HOST
+          value: {{ .APIServerHost }}
+        - name: KUBERNETES_SERVICE_PORT
+          value: "{{ .APIServerPort }}"
+        volumeMounts:
+        - name: cni-cfg
+          mountPath: /etc/cni/net.d
+        - name: xtables-lock
+          mountPath: /run/xtables.lock
+          readOnly: false
+        - name: lib-modules
+          mountPath: /lib/modules
+          readOnly: true
+        resources:
+          requests:
+            cpu: "100m"
+            memory: "50Mi"
+          limits:
+            cpu: "100m"
+            memory: "50Mi"
+        securityContext:
+          privileged: false
+          capabilities:
+            add: ["NET_RAW", "NET_ADMIN"]
+      volumes:
+      - name: cni-cfg
+        hostPath:
+          path: /etc/cni/net.d
+          type: DirectoryOrCreate
+      - name: xtables-lock
+        hostPath:
+          path: /run/xtables.lock
+          type: FileOrCreate
+      - name: lib-modules
+        hostPath:
+          path: /lib/modules
+`
+
+// flannelEdgeManifest runs flannel on edge nodes with the host-gw backend,
+// it talks to the API server through the edgecore metaServer as described
+// in the KubeEdge docs. The delegate is ptp, as the kind node image ships
+// no bridge plugin
+const flannelEdgeManifest = `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: flannel-edge
+  namespace: kube-system
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: flannel-edge
+rules:
+- apiGroups: [""]
+  resources: ["pods"]
+  verbs: ["get"]
+- apiGroups: [""]
+  resources: ["nodes"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: [""]
+  resources: ["nodes/status"]
+  verbs: ["patch"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: flannel-edge
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: flannel-edge
+subjects:
+- kind: ServiceAccount
+  name: flannel-edge
+  namespace: kube-system
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: kube-flannel-edge-cfg
+  namespace: kube-system
+data:
+  cni-conf.json: |
+    {
+      "name": "cbr0",
+      "cniVersion": "0.3.1",
+      "plugins": [
+        {
+          "type": "flannel",
+          "delegate": {
+            "type": "ptp"
+          }
+        },
+        {
+          "type": "portmap",
+          "capabilities": {"portMappings": true}
+        }
+      ]
+    }
+  net-conf.json: |
+    {
+      "Network": "{{ .PodSubnet }}",
+      "Backend": {
+        "Type": "host-gw"
+      }
+    }
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: kube-flannel-edge
+  namespace: kube-system
+  labels:
+    tier: node
+    app: flannel-edge
+spec:
+  selector:
+    matchLabels:
+      app: flannel-edge
+  template:
+    metadata:
+      labels:
+        tier: node
+        app: flannel-edge
+    spec:
+      hostNetwork: true
+      nodeSelector:
+        kubernetes.io/os: linux
+        node-role.kubernetes.io/edge: ""
+      tolerations:
+      - operator: Exists
+      serviceAccountName: flannel-edge
+      initContainers:
+      - name: install-cni-plugin
+        image: docker.io/flannel/flannel-cni-plugin:v1.4.0-flannel1
+        command: ["cp", "-f", "/flannel", "/opt/cni/bin/flannel"]
+        volumeMounts:
+        - name: cni-plugin
+          mountPath: /opt/cni/bin
+      - name: install-cni
+        image: docker.io/flannel/flannel:v0.24.2
+        command: ["cp", "-f", "/etc/kube-flannel/cni-conf.json", "/etc/cni/net.d/10-flannel.conflist"]
+        volumeMounts:
+        - name: cni
+          mountPath: /etc/cni/net.d
+        - name: flannel-cfg
+          mountPath: /etc/kube-flannel/
+      containers:
+      - name: kube-flannel
+        image: docker.io/flannel/flannel:v0.24.2
+        command: ["/opt/bin/flanneld"]
+        args:
+        - --ip-masq
+        - --kube-subnet-mgr
+        - --kube-api-url=http://127.0.0.1:10550
+        env:
+        - name: POD_NAME
+          valueFrom:
+            fieldRef:
+              fieldPath: metadata.name
+        - name: POD_NAMESPACE
+          valueFrom:
+            fieldRef:
+              fieldPath: metadata.namespace
+        - name: EVENT_QUEUE_DEPTH
+          value: "5000"
+        securityContext:
+          privileged: false
+          capabilities:
+            add: ["NET_ADMIN", "NET_RAW"]
+        volumeMounts:
+        - name: run
+          mountPath: /run/flannel
+        - name: flannel-cfg
+          mountPath: /etc/kube-flannel/
+        - name: xtables-lock
+          mountPath: /run/xtables.lock
+      volumes:
+      - name: run
+        hostPath:
+          path: /run/flannel
+      - name: cni-plugin
+        hostPath:
+          path: /opt/cni/bin
+      - name: cni
+        hostPath:
+          path: /etc/cni/net.d
+      - name: flannel-cfg
+        configMap:
+          name: kube-flannel-edge-cfg
+      - name: xtables-lock
+        hostPath:
+          path: /run/xtables.lock
+          type: FileOrCreate
+`
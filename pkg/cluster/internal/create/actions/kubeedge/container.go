@@ -0,0 +1,145 @@
+package kubeedge
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/shared/create/actions"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+)
+
+// cloudCoreRoute redirects a cloudcore port to the NodePort of the cloudcore
+// Service, which keadm deploys without host networking in container mode
+type cloudCoreRoute struct {
+	protocol string
+	port     string
+	nodePort string
+}
+
+// cloudCoreNodePortValues are the keadm chart values setting the NodePorts
+// of the cloudcore Service, by cloudcore port
+var cloudCoreNodePortValues = []struct {
+	port  int32
+	value string
+}{
+	{10000, "cloudCore.service.cloudhubNodePort"},
+	{10001, "cloudCore.service.cloudhubQuicNodePort"},
+	{10002, "cloudCore.service.cloudhubHttpsNodePort"},
+	{10003, "cloudCore.service.cloudstreamNodePort"},
+	{10004, "cloudCore.service.tunnelNodePort"},
+}
+
+// keadmNodePortSettings returns the keadm init arguments pinning the
+// NodePorts of the cloudcore Service to constants.CloudCoreNodePorts
+func keadmNodePortSettings() string {
+	settings := []string{}
+	for _, v := range cloudCoreNodePortValues {
+		settings = append(settings, fmt.Sprintf("--set %s=%d", v.value, constants.CloudCoreNodePorts[v.port]))
+	}
+	return strings.Join(settings, " ")
+}
+
+// checkExposedNodePorts checks that the cloudcore Service serves the ports
+// mapped to the host by --expose-cloudcore, which were chosen before it existed
+func checkExposedNodePorts(routes []cloudCoreRoute) error {
+	for _, v := range cloudCoreNodePortValues {
+		port, ok := nodePort(routes, int(v.port))
+		if !ok {
+			return fmt.Errorf("the cloudcore service has no NodePort for port %d", v.port)
+		}
+		if port != fmt.Sprint(constants.CloudCoreNodePorts[v.port]) {
+			return fmt.Errorf("the cloudcore service serves port %d at NodePort %s, but NodePort %d is mapped to the host",
+				v.port, port, constants.CloudCoreNodePorts[v.port])
+		}
+	}
+	return nil
+}
+
+// cloudCoreRoutes reads the NodePorts of the cloudcore Service, once the
+// cloudcore deployed by keadm is up
+func cloudCoreRoutes(ctx *actions.ActionContext, controlPlane nodes.Node) ([]cloudCoreRoute, error) {
+	cmd := controlPlane.Command("kubectl", "rollout", "status", "deployment/cloudcore", "-n", "kubeedge", "--timeout=300s")
+	lines, err := exec.CombinedOutputLines(cmd)
+	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		return nil, errors.Wrap(err, "cloudcore is not ready")
+	}
+
+	cmd = controlPlane.Command("kubectl", "get", "service", "cloudcore", "-n", "kubeedge",
+		`-o=jsonpath={range .spec.ports[*]}{.protocol} {.port} {.nodePort}{"\n"}{end}`)
+	lines, err = exec.OutputLines(cmd)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the cloudcore service")
+	}
+
+	routes := []cloudCoreRoute{}
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		routes = append(routes, cloudCoreRoute{
+			protocol: strings.ToLower(fields[0]),
+			port:     fields[1],
+			nodePort: fields[2],
+		})
+	}
+	if len(routes) == 0 {
+		return nil, errors.New("the cloudcore service has no NodePorts")
+	}
+	return routes, nil
+}
+
+// nodePort returns the NodePort of the tcp cloudcore port
+func nodePort(routes []cloudCoreRoute, port int) (string, bool) {
+	for _, route := range routes {
+		if route.protocol == "tcp" && route.port == fmt.Sprint(port) {
+			return route.nodePort, true
+		}
+	}
+	return "", false
+}
+
+// routeToCloudCore redirects the traffic of the edge node to the cloudcore
+// ports of the advertised address to the NodePorts on the control-plane
+// node, so that edgecore can use the usual ports in container mode
+func routeToCloudCore(ctx *actions.ActionContext, node nodes.Node, advertisedIP, nodeIP string, routes []cloudCoreRoute) error {
+	for _, route := range routes {
+		rule := fmt.Sprintf("OUTPUT -d %s -p %s --dport %s -j DNAT --to-destination %s:%s -m comment --comment keink-cloudcore",
+			advertisedIP, route.protocol, route.port, nodeIP, route.nodePort)
+		cmd := node.Command("bash", "-c", fmt.Sprintf("iptables -t nat -C %[1]s 2>/dev/null || iptables -t nat -A %[1]s", rule))
+		lines, err := exec.CombinedOutputLines(cmd)
+		ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+		if err != nil {
+			return errors.Wrapf(err, "failed to route port %s to cloudcore on %s", route.port, node)
+		}
+	}
+	return nil
+}
+
+// preloadedCloudCoreImage returns the cloudcore image preloaded in the node
+// image, or an empty string if the node image has none
+func preloadedCloudCoreImage(node nodes.Node) (string, error) {
+	cmd := node.Command("bash", "-c", fmt.Sprintf("cat %s 2>/dev/null || true", constants.CloudCoreImageFile))
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read the preloaded cloudcore image")
+	}
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return strings.TrimSpace(lines[0]), nil
+}
+
+// splitImage splits an image reference into its repository and tag
+func splitImage(image string) (string, string) {
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return image, "latest"
+	}
+	return image[:i], image[i+1:]
+}
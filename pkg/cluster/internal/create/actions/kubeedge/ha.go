@@ -0,0 +1,98 @@
+package kubeedge
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/cluster/shared/create/actions"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// loadBalancerConfigFile is the haproxy config of the kind external load balancer
+const loadBalancerConfigFile = "/usr/local/etc/haproxy/haproxy.cfg"
+
+// the markers around the cloudcore part of the load balancer config, so it
+// can be written again on a resumed phase
+const (
+	loadBalancerConfigBegin = "# keink cloudcore begin"
+	loadBalancerConfigEnd   = "# keink cloudcore end"
+)
+
+// cloudCoreLoadBalancedPorts are the cloudhub websocket and https ports
+// edge nodes connect to, haproxy can't balance the quic (udp) port
+var cloudCoreLoadBalancedPorts = []int{10000, 10002}
+
+// bootstrapCloudCoreHA runs cloudcore on every control-plane node, behind the
+// kind external load balancer. The first cloudcore stores the CA and the
+// server certificate, valid for all the addresses, in secrets the others reuse
+func (a *Action) bootstrapCloudCoreHA(ctx *actions.ActionContext, controlPlanes []nodes.Node, loadBalancer nodes.Node) error {
+	lbIP, _, err := loadBalancer.IP()
+	if err != nil {
+		return errors.Wrap(err, "failed to get the load balancer IP")
+	}
+
+	addresses := append([]string{lbIP}, a.extraAddresses()...)
+	for _, node := range controlPlanes {
+		ip, _, err := node.IP()
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the IP of %s", node)
+		}
+		addresses = append(addresses, ip)
+	}
+
+	for _, node := range controlPlanes {
+		if err := a.startCloudcore(ctx, node, addresses); err != nil {
+			return fmt.Errorf("failed to start cloudcore on %s: %v", node, err)
+		}
+	}
+
+	return configureLoadBalancer(ctx, loadBalancer, controlPlanes)
+}
+
+// configureLoadBalancer adds the cloudcore ports of every control-plane
+// node to the haproxy config of the load balancer and reloads it
+func configureLoadBalancer(ctx *actions.ActionContext, loadBalancer nodes.Node, controlPlanes []nodes.Node) error {
+	current, err := exec.Output(loadBalancer.Command("cat", loadBalancerConfigFile))
+	if err != nil {
+		return errors.Wrap(err, "failed to read the load balancer config")
+	}
+
+	config := string(current)
+	if begin := strings.Index(config, loadBalancerConfigBegin); begin >= 0 {
+		if end := strings.Index(config, loadBalancerConfigEnd); end > begin {
+			config = config[:begin] + config[end+len(loadBalancerConfigEnd):]
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(config, "\n") + "\n\n" + loadBalancerConfigBegin + "\n")
+	for _, port := range cloudCoreLoadBalancedPorts {
+		fmt.Fprintf(&b, "frontend cloudcore-%[1]d\n  bind *:%[1]d\n  default_backend cloudcore-%[1]d\n\n", port)
+		fmt.Fprintf(&b, "backend cloudcore-%d\n  option tcp-check\n", port)
+		for _, node := range controlPlanes {
+			ip, _, err := node.IP()
+			if err != nil {
+				return errors.Wrapf(err, "failed to get the IP of %s", node)
+			}
+			fmt.Fprintf(&b, "  server %s %s:%d check inter 2s fall 2 rise 2\n", node, ip, port)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(loadBalancerConfigEnd + "\n")
+
+	if err := nodeutils.WriteFile(loadBalancer, loadBalancerConfigFile, b.String()); err != nil {
+		return errors.Wrap(err, "failed to write the load balancer config")
+	}
+
+	// haproxy reloads its config on SIGHUP, like kind does after writing it
+	cmd := loadBalancer.Command("kill", "-s", "HUP", "1")
+	lines, err := exec.CombinedOutputLines(cmd)
+	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		return errors.Wrap(err, "failed to reload the load balancer")
+	}
+	return nil
+}
@@ -29,6 +29,11 @@ import (
 	"sigs.k8s.io/kind/pkg/cluster/shared/providers/docker"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
+
+	"github.com/kubeedge/keink/pkg/apis/config/edge"
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+	"github.com/kubeedge/keink/pkg/cluster/internal/edgecore"
+	"github.com/kubeedge/keink/pkg/cluster/internal/resources"
 )
 
 // controlPlaneIP is IP address that edgecore register to
@@ -37,17 +42,69 @@ var controlPlaneIP = ""
 // KubeEdgeToken is token that edgecore used to register to cloudcore
 var KubeEdgeToken string
 
-// Action implements action for creating the node config files
-type Action struct {
+// Options holds the KubeEdge customized settings of the action
+type Options struct {
 	AdvertiseAddress string
 	ContainerMode    bool
+
+	// ExposedAddress is the host address the cloudcore ports are mapped
+	// to, cloudcore certificates are also issued for it
+	ExposedAddress string
+
+	// CACert and CAKey are the PEM encoded CA cloudcore issues its server
+	// certificate and the edge certificates with, cloudcore generates its
+	// own CA when they are empty
+	CACert []byte
+	CAKey  []byte
+
+	// Resume continues a retained cluster from the first phase that has
+	// not completed, instead of running every phase
+	Resume bool
+	// SkipPhases lists the phases that should not run at all
+	SkipPhases []string
+
+	// EnableStream turns on cloudStream and edgeStream, the tunnel the API
+	// server uses to reach pods on edge nodes
+	EnableStream bool
+	// EnableDynamicController turns on the cloudcore dynamicController,
+	// which serves list-watch to the edgecore metaServer
+	EnableDynamicController bool
+
+	// EdgeCNI is the pod network setup of the edge nodes, see constants.EdgeCNIs
+	EdgeCNI string
+
+	// EdgeNodes are the keink specific settings of the edge nodes, by node name
+	EdgeNodes map[string]edge.Node
+
+	// MQTTMode is the MQTT broker of the edgecore eventBus, see constants.MQTTModes
+	MQTTMode string
+
+	// EnableRouter turns on the cloudcore router and the edgecore
+	// serviceBus, which carry the messages of the Rule API
+	EnableRouter bool
+}
+
+// Action implements action for creating the node config files
+type Action struct {
+	Options
+
+	// state records the completed phases on the control-plane node
+	state *phaseState
+
+	// in container mode, edge nodes reach cloudcore through these NodePorts
+	// of the cloudcore Service on the control-plane node
+	cloudCoreRoutes []cloudCoreRoute
+	cloudCoreNodeIP string
+
+	// mqttBroker is the address of the external MQTT broker, if the
+	// MQTTMode uses one
+	mqttBroker string
 }
 
 // NewAction returns a new action for creating the config files
-func NewAction(address string, containerMode bool) actions.Action {
+func NewAction(opts Options) actions.Action {
 	return &Action{
-		AdvertiseAddress: address,
-		ContainerMode:    containerMode,
+		Options: opts,
 	}
 }
 
@@ -56,21 +113,23 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	ctx.Status.Start("Starting KubeEdge 📜")
 	defer ctx.Status.End(false)
 
-	if err := a.preProcess(ctx); err != nil {
-		return fmt.Errorf("failed do pre process: %v", err)
+	state, err := loadPhaseState(ctx)
+	if err != nil {
+		return err
+	}
+	a.state = state
+
+	if a.ExposedAddress != "" {
+		if err := recordExposedAddress(state.node, a.ExposedAddress); err != nil {
+			return err
+		}
 	}
 
 	// How to start cloudcore and edgecore localhost
 	// The below logic is from kubeedge hack/local-up-kubeedge.sh
 	// or from `keadm init/join` logic
-
-	// bootstrap cloudcore: this operation should be on control-plane
-	if err := a.BootstrapCloudCore(ctx); err != nil {
-		return err
-	}
-
-	// bootstrap edgecore: this operation should be on edge-node
-	if err := a.BootstrapEdgecore(ctx); err != nil {
+	// every step is a named phase, see phases()
+	if err := a.runPhases(ctx); err != nil {
 		return err
 	}
 
@@ -106,7 +165,8 @@ var patch = `"spec": {
 // this patch cmd is from the above json
 var kubeProxyNotScheduleOnEdgeNode string = `kubectl patch daemonset kube-proxy -n kube-system -p '{"spec": {"template": {"spec": {"affinity": {"nodeAffinity": {"requiredDuringSchedulingIgnoredDuringExecution": {"nodeSelectorTerms": [{"matchExpressions": [{"key": "node-role.kubernetes.io/edge", "operator": "DoesNotExist"}]}]}}}}}}}'`
 
-func (a *Action) preProcess(ctx *actions.ActionContext) error {
+// waitControlPlaneReady waits for the control-plane node to be Ready
+func (a *Action) waitControlPlaneReady(ctx *actions.ActionContext) error {
 	allNodes, err := ctx.Nodes()
 	if err != nil {
 		return err
@@ -126,17 +186,25 @@ func (a *Action) preProcess(ctx *actions.ActionContext) error {
 	if err != nil {
 		return errors.Wrap(err, fmt.Sprintf("failed to wait the control-plane ready %v ", lines))
 	}
+	return nil
+}
 
-	//cmd = node.Command("bash", "-c", kindnetNotScheduleOnEdgeNode)
-	//lines, err = exec.CombinedOutputLines(cmd)
-	//ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
-	//if err != nil {
-	//	return errors.Wrap(err, fmt.Sprintf("failed to stop kindnet scheduled to edge nodes %v ", lines))
-	//}
+// patchKubeProxy keeps kube-proxy from being scheduled to the edge nodes
+func (a *Action) patchKubeProxy(ctx *actions.ActionContext) error {
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
 
-	// edge-node not schedule kube-proxy
-	cmd = node.Command("bash", "-c", kubeProxyNotScheduleOnEdgeNode)
-	lines, err = exec.CombinedOutputLines(cmd)
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+
+	// edge-node not schedule kube-proxy, kindnet is kept away from the
+	// edge nodes in the edge-cni phase
+	cmd := node.Command("bash", "-c", kubeProxyNotScheduleOnEdgeNode)
+	lines, err := exec.CombinedOutputLines(cmd)
 	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
 	if err != nil {
 		return errors.Wrap(err, fmt.Sprintf("failed to stop daemonset kube-proxy scheduled to the edge nodes %v ", lines))
@@ -155,12 +223,36 @@ func (a *Action) BootstrapCloudCore(ctx *actions.ActionContext) error {
 		return err
 	}
 
+	// with several control-plane nodes, run cloudcore on each of them
+	// behind the kind external load balancer
+	loadBalancer, err := nodeutils.ExternalLoadBalancerNode(allNodes)
+	if err != nil {
+		return err
+	}
+	if loadBalancer != nil {
+		controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+		if err != nil {
+			return err
+		}
+		return a.bootstrapCloudCoreHA(ctx, controlPlanes, loadBalancer)
+	}
+
 	if a.ContainerMode {
 		if err := a.startCloudcoreWithKeadm(ctx, node); err != nil {
 			return fmt.Errorf("failed to start cloudcore with keadm: %v", err)
 		}
 	} else {
-		if err := a.startCloudcore(ctx, node); err != nil {
+		// cloudcore defaults to the node IP, which is enough unless it is
+		// also reached at the exposed host address
+		var addresses []string
+		if a.ExposedAddress != "" {
+			ip, _, err := node.IP()
+			if err != nil {
+				return fmt.Errorf("failed to get control-plane IP: %v", err)
+			}
+			addresses = append([]string{ip}, a.extraAddresses()...)
+		}
+		if err := a.startCloudcore(ctx, node, addresses); err != nil {
 			return fmt.Errorf("failed to start cloudcore: %v", err)
 		}
 	}
@@ -173,25 +265,97 @@ func (a *Action) startCloudcoreWithKeadm(ctx *actions.ActionContext, node nodes.
 	// master nodes support running cloudcore
 	// TODO: due to cloudcore can be deployed on word nodes, how to access them when edge node register(dynamic IP address)
 
-	// use keadm init to install cloudcore in container mode
-	// cloudcore svc use NodePort type, to enable edgecore connect to cloudcore, we may add the below routes on the host
-	//iptables -t nat -A PREROUTING -d ${advertise-address} -p tcp --dport 10000 -j DNAT --to-destination ${NODE_IP}:30000
-	//iptables -t nat -A PREROUTING -d ${advertise-address} -p tcp --dport 10002 -j DNAT --to-destination ${NODE_IP}:30002
-	startCmd := fmt.Sprintf("keadm init --advertise-address=%s --profile version=v1.12.0 --kube-config /etc/kubernetes/admin.conf --set cloudCore.hostNetWork=false", a.AdvertiseAddress)
-	cmd := node.Command("bash", "-c", startCmd)
-	lines, err := exec.CombinedOutputLines(cmd)
-	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+	// use keadm init to install cloudcore in container mode, the cloudcore
+	// Service is a NodePort one, edge nodes are routed to it when they join.
+	// The certificate must be valid for the control-plane IP they connect to
+	ip, _, err := node.IP()
 	if err != nil {
-		return fmt.Errorf("failed to keadm init: %v", err)
+		return fmt.Errorf("failed to get control-plane IP: %v", err)
+	}
+	addresses := strings.Join(append([]string{ip}, a.extraAddresses()...), ",")
+	startCmd := fmt.Sprintf("keadm init --advertise-address=%s --profile version=v1.12.0 --kube-config /etc/kubernetes/admin.conf --set cloudCore.hostNetWork=false %s", addresses, keadmNodePortSettings())
+	if a.EnableDynamicController {
+		startCmd += " --set cloudCore.modules.dynamicController.enable=true"
+	}
+	if a.EnableStream {
+		startCmd += " --set cloudCore.modules.cloudStream.enable=true"
+	}
+	if a.EnableRouter {
+		startCmd += " --set cloudCore.modules.router.enable=true"
+	}
+	if len(a.nodeGroups()) > 0 {
+		startCmd += " --set controllerManager.enable=true"
+	}
+
+	// run the cloudcore built along with the node image when it has one,
+	// keink installs the stream rules itself so the iptables-manager,
+	// which would be pulled too, is left out
+	image, err := preloadedCloudCoreImage(node)
+	if err != nil {
+		return err
+	}
+	if image != "" {
+		repository, tag := splitImage(image)
+		startCmd += fmt.Sprintf(" --set cloudCore.image.repository=%s --set cloudCore.image.tag=%s --set cloudCore.image.pullPolicy=Never --set iptablesManager.enable=false", repository, tag)
+	}
+
+	// keadm init fails once cloudcore is installed, which is the case when
+	// a resumed cloudcore phase had got past it
+	if err := node.Command("kubectl", "get", "deployment", "cloudcore", "-n", "kubeedge").Run(); err == nil {
+		ctx.Logger.V(1).Info("cloudcore is already installed, skipping keadm init")
+	} else {
+		cmd := node.Command("bash", "-c", startCmd)
+		lines, err := exec.CombinedOutputLines(cmd)
+		ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+		if err != nil {
+			return fmt.Errorf("failed to keadm init: %v", err)
+		}
+	}
+
+	if a.ExposedAddress != "" {
+		routes, err := cloudCoreRoutes(ctx, node)
+		if err != nil {
+			return err
+		}
+		if err := checkExposedNodePorts(routes); err != nil {
+			return err
+		}
+	}
+
+	if a.EnableStream {
+		routes, err := cloudCoreRoutes(ctx, node)
+		if err != nil {
+			return err
+		}
+		port, ok := nodePort(routes, streamPort)
+		if !ok {
+			return fmt.Errorf("the cloudcore service has no NodePort for the stream port %d", streamPort)
+		}
+		if err := installStreamRules(ctx, ip+":"+port); err != nil {
+			return err
+		}
 	}
 
 	return getToken(node)
 }
 
-// startCloudcore on control plane
-func (a *Action) startCloudcore(ctx *actions.ActionContext, node nodes.Node) error {
-	// create ns kubeedge
-	cmd := node.Command("kubectl", "create", "ns", "kubeedge")
+// extraAddresses returns the addresses cloudcore is reached at besides the
+// control-plane node IPs
+func (a *Action) extraAddresses() []string {
+	addresses := []string{}
+	for _, address := range []string{a.AdvertiseAddress, a.ExposedAddress} {
+		if address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+// startCloudcore on control plane, advertiseAddresses replaces the addresses
+// cloudcore issues its server certificate for when it is set
+func (a *Action) startCloudcore(ctx *actions.ActionContext, node nodes.Node, advertiseAddresses []string) error {
+	// create ns kubeedge, tolerating a namespace left by an earlier attempt
+	cmd := node.Command("bash", "-c", "kubectl create ns kubeedge --dry-run=client -o yaml | kubectl apply -f -")
 	lines, err := exec.CombinedOutputLines(cmd)
 	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
 	if err != nil {
@@ -211,7 +375,7 @@ func (a *Action) startCloudcore(ctx *actions.ActionContext, node nodes.Node) err
 	// CRDs are copied to image when build image
 	for _, crd := range crds {
 		crdPath := filepath.Join("/etc/kubeedge/crds/", crd)
-		cmd = node.Command("kubectl", "create", "-f", crdPath)
+		cmd = node.Command("kubectl", "apply", "-f", crdPath)
 		lines, err := exec.CombinedOutputLines(cmd)
 		ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
 		if err != nil {
@@ -240,7 +404,44 @@ func (a *Action) startCloudcore(ctx *actions.ActionContext, node nodes.Node) err
 		return fmt.Errorf("failed to modify kubeconfig: %v", err)
 	}
 
-	cmd = node.Command("bash", "-c", "systemctl daemon-reload && systemctl enable cloudcore && systemctl start cloudcore")
+	if len(a.CACert) > 0 {
+		if err := a.installCA(node); err != nil {
+			return err
+		}
+	}
+
+	if len(advertiseAddresses) > 0 {
+		cmd = node.Command("bash", "-c", fmt.Sprintf(`sed -i '/advertiseAddress:/{n;s|\( *\)- .*|\1- %s|;}' /etc/kubeedge/config/cloudcore.yaml`, strings.Join(advertiseAddresses, `\n\1- `)))
+		lines, err = exec.CombinedOutputLines(cmd)
+		ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+		if err != nil {
+			return fmt.Errorf("failed to modify advertiseAddress: %v", err)
+		}
+	}
+
+	if a.EnableDynamicController {
+		cmd = node.Command("bash", "-c", `sed -i '/dynamicController:/{n;s/false/true/;}' /etc/kubeedge/config/cloudcore.yaml`)
+		lines, err = exec.CombinedOutputLines(cmd)
+		ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+		if err != nil {
+			return fmt.Errorf("failed to enable dynamicController: %v", err)
+		}
+	}
+
+	if a.EnableStream {
+		if err := a.enableCloudStream(ctx, node); err != nil {
+			return err
+		}
+	}
+
+	if a.EnableRouter {
+		if err := enableRouter(ctx, node); err != nil {
+			return err
+		}
+	}
+
+	// restart rather than start, so that a resumed phase picks up the new config
+	cmd = node.Command("bash", "-c", "systemctl daemon-reload && systemctl enable cloudcore && systemctl restart cloudcore")
 	lines, err = exec.CombinedOutputLines(cmd)
 	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
 	if err != nil {
@@ -265,10 +466,33 @@ func (a *Action) BootstrapEdgecore(ctx *actions.ActionContext) error {
 	ip, _, _ := controlPlane.IP()
 	controlPlaneIP = ip
 
+	// edge nodes reach the highly available cloudcore through the load balancer
+	loadBalancer, err := nodeutils.ExternalLoadBalancerNode(allNodes)
+	if err != nil {
+		return err
+	}
+	if loadBalancer != nil {
+		ip, _, err := loadBalancer.IP()
+		if err != nil {
+			return errors.Wrap(err, "failed to get the load balancer IP")
+		}
+		controlPlaneIP = ip
+	}
+
 	if a.AdvertiseAddress != "" {
 		controlPlaneIP = a.AdvertiseAddress
 	}
 
+	// cloudcore deployed by keadm is only reachable through its NodePorts
+	if a.ContainerMode {
+		routes, err := cloudCoreRoutes(ctx, controlPlane)
+		if err != nil {
+			return err
+		}
+		a.cloudCoreRoutes = routes
+		a.cloudCoreNodeIP = ip
+	}
+
 	// then join edge nodes if any
 	// The below operation, we should exec in the edge nodes, but not master
 	edgeNodes, err := docker.ListEdgeNodesByLabel(ctx.Config.Name)
@@ -280,6 +504,22 @@ func (a *Action) BootstrapEdgecore(ctx *actions.ActionContext) error {
 		//return fmt.Errorf("edge node not exist")
 	}
 
+	// the token is only kept in memory, so fetch it again when the
+	// cloudcore phase ran in an earlier attempt
+	if len(edgeNodes) > 0 && KubeEdgeToken == "" {
+		if err := getToken(controlPlane); err != nil {
+			return err
+		}
+	}
+
+	if len(edgeNodes) > 0 && a.MQTTMode != "" && a.MQTTMode != constants.MQTTModeInternal {
+		broker, err := a.startMQTTBroker(ctx)
+		if err != nil {
+			return err
+		}
+		a.mqttBroker = broker
+	}
+
 	if len(edgeNodes) > 0 {
 		if err := a.joinEdgeNodes(ctx, edgeNodes); err != nil {
 			return err
@@ -297,11 +537,21 @@ func (a *Action) joinEdgeNodes(
 	fns := []func() error{}
 	for _, node := range edgeNodes {
 		node := node // capture loop variable
+		if a.Resume && a.state.completed(edgeNodeStep(node)) {
+			ctx.Logger.V(0).Infof("Skipping edge node %s, already joined", node.String())
+			continue
+		}
 		fns = append(fns, func() error {
+			var err error
 			if a.ContainerMode {
-				return a.runStartEdgecoreWithKeadm(ctx, node)
+				err = a.runStartEdgecoreWithKeadm(ctx, node)
+			} else {
+				err = a.runStartEdgecore(ctx, node)
+			}
+			if err != nil {
+				return err
 			}
-			return a.runStartEdgecore(ctx, node)
+			return a.state.markCompleted(edgeNodeStep(node))
 		})
 	}
 	if err := errors.UntilErrorConcurrent(fns); err != nil {
@@ -318,81 +568,48 @@ func (a *Action) runStartEdgecore(ctx *actions.ActionContext, node nodes.Node) e
 		return errors.Wrap(err, "failed to stop kubelet")
 	}
 
-	// generate config
-	cmd := node.Command("bash", "-c", "edgecore --defaultconfig > /etc/kubeedge/config/edgecore.yaml")
-	lines, err := exec.CombinedOutputLines(cmd)
-	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
-	if err != nil {
-		return fmt.Errorf("failed to generate cloudcore config: %v", err)
-	}
-
-	cmd = node.Command("bash", "-c", fmt.Sprintf(`sed -i -e "/metaServer:/{n;n;s/false/true/;}" /etc/kubeedge/config/edgecore.yaml`))
-	lines, err = exec.CombinedOutputLines(cmd)
-	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
-	if err != nil {
-		return fmt.Errorf("failed to modify metaServer: %v", err)
+	limits := a.EdgeNodes[node.String()].Resources
+	if limits != nil {
+		if err := resources.Limit(node, limits); err != nil {
+			return err
+		}
 	}
 
-	cmd = node.Command("bash", "-c", fmt.Sprintf(`sed -i -e "s|cgroupDriver: .*|cgroupDriver: systemd|g" /etc/kubeedge/config/edgecore.yaml`))
-	lines, err = exec.CombinedOutputLines(cmd)
-	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+	rt, err := edgecore.StartRuntime(ctx.Logger, node, a.EdgeNodes[node.String()].Runtime)
 	if err != nil {
-		return fmt.Errorf("failed to modify cgroupDriver: %v", err)
+		return err
 	}
-
-	cmd = node.Command("bash", "-c", fmt.Sprintf(`sed -i -e "s|imageServiceEndpoint: .*|imageServiceEndpoint: %s|g" /etc/kubeedge/config/edgecore.yaml`, "unix:///var/run/containerd/containerd.sock"))
-	lines, err = exec.CombinedOutputLines(cmd)
-	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+	driver, err := edgecore.CgroupDriver(ctx.Logger, node, rt)
 	if err != nil {
-		return fmt.Errorf("failed to modify imageServiceEndpoint to remote: %v", err)
+		return err
 	}
 
-	cmd = node.Command("bash", "-c", fmt.Sprintf(`sed -i -e "s|containerRuntimeEndpoint: .*|containerRuntimeEndpoint: %s|g" /etc/kubeedge/config/edgecore.yaml`, "unix:///var/run/containerd/containerd.sock"))
-	lines, err = exec.CombinedOutputLines(cmd)
-	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
-	if err != nil {
-		return fmt.Errorf("failed to modify containerRuntimeEndpoint to remote: %v", err)
+	cfg := edgecore.Config{
+		CloudCore:        controlPlaneIP + ":10000",
+		Token:            KubeEdgeToken,
+		Runtime:          rt,
+		CgroupDriver:     driver,
+		MQTTMode:         edgecore.MQTTMode(a.MQTTMode),
+		MQTTBroker:       a.mqttBroker,
+		EnableServiceBus: a.EnableRouter,
 	}
-
-	// modify edgeHub.httpServer websocker.server ip cloudcore ip or control-plane ip
-	cmd = node.Command("bash", "-c", fmt.Sprintf(`sed -i -e "s|httpServer: .*|httpServer: %s|g" /etc/kubeedge/config/edgecore.yaml`, "https://"+controlPlaneIP+":10002"))
-	lines, err = exec.CombinedOutputLines(cmd)
-	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
-	if err != nil {
-		return fmt.Errorf("failed to modify httpServer: %v", err)
+	if a.EnableStream {
+		cfg.EdgeStreamServer = fmt.Sprintf("%s:%d", controlPlaneIP, tunnelPort)
 	}
-	cmd = node.Command("bash", "-c", fmt.Sprintf(`sed -i -e "s|server: .*10000|server: %s|g" /etc/kubeedge/config/edgecore.yaml`, controlPlaneIP+":10000"))
-	lines, err = exec.CombinedOutputLines(cmd)
-	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
-	if err != nil {
-		return fmt.Errorf("failed to modify httpServer: %v", err)
+	if err := edgecore.Configure(ctx.Logger, node, cfg); err != nil {
+		return err
 	}
-
-	cmd = node.Command("bash", "-c", `sed -i -e "s|mqttMode: .*|mqttMode: 0|g" /etc/kubeedge/config/edgecore.yaml`)
-	lines, err = exec.CombinedOutputLines(cmd)
-	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
-	if err != nil {
-		return fmt.Errorf("failed to modify mqttMode: %v", err)
+	if err := edgecore.CheckCgroupDriver(node, rt); err != nil {
+		return err
 	}
-
-	cmd = node.Command("bash", "-c", `sed -i -e "s|/tmp/etc/resolv|/etc/resolv|g" /etc/kubeedge/config/edgecore.yaml`)
-	lines, err = exec.CombinedOutputLines(cmd)
-	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
-	if err != nil {
-		return fmt.Errorf("failed to modify resolv: %v", err)
+	if limits != nil {
+		if err := resources.ReserveInEdgeCore(node, limits); err != nil {
+			return err
+		}
 	}
 
-	cmd = node.Command("bash", "-c", fmt.Sprintf(`sed -i -e "s|token: .*|token: %s|g" /etc/kubeedge/config/edgecore.yaml`, KubeEdgeToken))
-	lines, err = exec.CombinedOutputLines(cmd)
-	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
-	if err != nil {
-		return fmt.Errorf("failed to modify token: %v", err)
-	}
-	cmd = node.Command("bash", "-c", "systemctl daemon-reload && systemctl enable edgecore && systemctl start edgecore")
-	lines, err = exec.CombinedOutputLines(cmd)
-	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
-	if err != nil {
-		return fmt.Errorf("failed to start cloudcore: %v", err)
+	if err := edgecore.Start(ctx.Logger, node); err != nil {
+		return err
 	}
 
 	return waitNodeReady(ctx, node.String())
@@ -404,6 +621,31 @@ func (a *Action) runStartEdgecoreWithKeadm(ctx *actions.ActionContext, node node
 		return errors.Wrap(err, "failed to stop kubelet")
 	}
 
+	limits := a.EdgeNodes[node.String()].Resources
+	if limits != nil {
+		if err := resources.Limit(node, limits); err != nil {
+			return err
+		}
+	}
+
+	rt, err := edgecore.StartRuntime(ctx.Logger, node, a.EdgeNodes[node.String()].Runtime)
+	if err != nil {
+		return err
+	}
+	driver, err := edgecore.CgroupDriver(ctx.Logger, node, rt)
+	if err != nil {
+		return err
+	}
+	// keadm join starts edgecore right away, so check the driver it is
+	// given before joining
+	if err := edgecore.CheckRuntimeCgroupDriver(node, rt, driver); err != nil {
+		return err
+	}
+
+	if err := routeToCloudCore(ctx, node, controlPlaneIP, a.cloudCoreNodeIP, a.cloudCoreRoutes); err != nil {
+		return err
+	}
+
 	// rm /etc/kubeedge directory, or keadm join will report error
 	cmd := node.Command("bash", "-c", "rm -rf /etc/kubeedge")
 	lines, err := exec.CombinedOutputLines(cmd)
@@ -412,10 +654,17 @@ func (a *Action) runStartEdgecoreWithKeadm(ctx *actions.ActionContext, node node
 		return fmt.Errorf("failed to cleanup directory /etc/kubeedge: %v", err)
 	}
 
-	// not start MQTT conainer, error: E0728 01:07:37.717267    1429 remote_runtime.go:116] "RunPodSandbox from runtime service failed" err="rpc error: code = Unknown
-	// desc = failed to reserve sandbox name \"mqtt___0\": name \"mqtt___0\" is reserved for \"264c9ad4f0be7271711a21b0c89f958da582e1869a3b18fb07dd719b16989595\""
+	// the MQTT container keadm join used to run failed with: "RunPodSandbox from runtime service failed" err="rpc error: code = Unknown
+	// desc = failed to reserve sandbox name \"mqtt___0\"", so the eventBus uses the built-in broker or the keink one, see --mqtt-mode
 	// TODO: debug why edgecore segmentfault with nothing
-	joinCmd := fmt.Sprintf("keadm join --cgroupdriver=systemd --cloudcore-ipport=%s --token=%s --remote-runtime-endpoint=unix:///var/run/containerd/containerd.sock", controlPlaneIP+":10000", KubeEdgeToken)
+	joinCmd := fmt.Sprintf("keadm join --cgroupdriver=%s --cloudcore-ipport=%s --token=%s --remote-runtime-endpoint=%s", driver, controlPlaneIP+":10000", KubeEdgeToken, rt.Endpoint)
+	joinCmd += " --set " + a.keadmEventBusSettings()
+	if a.EnableRouter {
+		joinCmd += ",modules.serviceBus.enable=true"
+	}
+	if a.EnableStream {
+		joinCmd += fmt.Sprintf(",modules.edgeStream.enable=true,modules.edgeStream.server=%s:%d", controlPlaneIP, tunnelPort)
+	}
 	cmd = node.Command("bash", "-c", joinCmd)
 	lines, err = exec.CombinedOutputLines(cmd)
 	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
@@ -423,13 +672,25 @@ func (a *Action) runStartEdgecoreWithKeadm(ctx *actions.ActionContext, node node
 		return fmt.Errorf("failed to join edge: %v", err)
 	}
 
+	// keadm join writes the edgecore config and starts edgecore, restart it
+	// with the reservation
+	if limits != nil && (limits.CPUs != "" || limits.Memory != "") {
+		if err := resources.ReserveInEdgeCore(node, limits); err != nil {
+			return err
+		}
+		if err := node.Command("systemctl", "restart", "edgecore").Run(); err != nil {
+			return errors.Wrap(err, "failed to restart edgecore")
+		}
+	}
+
 	return waitNodeReady(ctx, node.String())
 }
 
 // stopKubelet stop kubelet service and delete kubelet node
 func stopKubelet(ctx *actions.ActionContext, node nodes.Node) error {
 	// first stop kubelet service on edge-node
-	cmd := node.Command("bash", "-c", "systemctl stop kubelet.service && systemctl disable kubelet.service && rm /etc/systemd/system/kubelet.service")
+	// the unit file is gone once this has run, so a resumed join skips it
+	cmd := node.Command("bash", "-c", "if [ -f /etc/systemd/system/kubelet.service ]; then systemctl stop kubelet.service && systemctl disable kubelet.service && rm /etc/systemd/system/kubelet.service; fi")
 	lines, err := exec.CombinedOutputLines(cmd)
 	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
 	if err != nil {
@@ -449,7 +710,7 @@ func stopKubelet(ctx *actions.ActionContext, node nodes.Node) error {
 		return err
 	}
 
-	s := fmt.Sprintf("kubectl delete node %s --wait", node.String())
+	s := fmt.Sprintf("kubectl delete node %s --wait --ignore-not-found", node.String())
 	delete := controlPlane.Command("bash", "-c", s)
 	lines, err = exec.CombinedOutputLines(delete)
 	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
@@ -497,15 +758,24 @@ func getToken(node nodes.Node) error {
 	// sleep 20s to wait cloudcore start successfully
 	// TODO: think a better way to do sync operation
 	time.Sleep(20 * time.Second)
+	token, err := ReadToken(node)
+	if err != nil {
+		return err
+	}
+
+	KubeEdgeToken = token
+	return nil
+}
+
+// ReadToken reads the token cloudcore issued from the tokensecret secret
+func ReadToken(node nodes.Node) (string, error) {
 	cmd := node.Command("bash", "-c", `kubectl get secret -nkubeedge tokensecret -o=jsonpath='{.data.tokendata}' | base64 -d`)
 	token, err := exec.Output(cmd)
 	if err != nil {
-		return fmt.Errorf("failed to get tokensecret: %v", err)
+		return "", fmt.Errorf("failed to get tokensecret: %v", err)
 	}
 	if string(token) == "" {
-		return fmt.Errorf("tokensecret cannot be empty")
+		return "", fmt.Errorf("tokensecret cannot be empty")
 	}
-
-	KubeEdgeToken = string(token)
-	return nil
+	return string(token), nil
 }
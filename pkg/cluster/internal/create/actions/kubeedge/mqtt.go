@@ -0,0 +1,64 @@
+package kubeedge
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/shared/create/actions"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+	"github.com/kubeedge/keink/pkg/cluster/internal/edgecore"
+)
+
+// mqttBrokerImage is the external broker shared by the edge nodes
+const mqttBrokerImage = "eclipse-mosquitto:2.0"
+
+// mqttBrokerPort is the port of the external broker
+const mqttBrokerPort = 1883
+
+// startMQTTBroker runs the external broker on the kind network, next to the
+// edge nodes, and returns its address. A broker left from an earlier
+// attempt is reused
+func (a *Action) startMQTTBroker(ctx *actions.ActionContext) (string, error) {
+	name := ctx.Config.Name + "-mqtt-broker"
+
+	state, err := exec.Output(exec.Command("docker", "inspect", "-f", "{{.State.Running}}", name))
+	switch {
+	case err != nil:
+		// mosquitto only listens on localhost without a config
+		script := fmt.Sprintf(`printf "listener %d\nallow_anonymous true\n" > /tmp/mosquitto.conf && exec mosquitto -c /tmp/mosquitto.conf`, mqttBrokerPort)
+		cmd := exec.Command("docker", "run", "-d",
+			"--name", name,
+			"--hostname", name,
+			"--network", "kind",
+			"--restart", "unless-stopped",
+			"--label", constants.ContainerClusterLabelKey+"="+ctx.Config.Name,
+			mqttBrokerImage, "sh", "-c", script)
+		lines, err := exec.CombinedOutputLines(cmd)
+		ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+		if err != nil {
+			return "", errors.Wrap(err, "failed to run the MQTT broker")
+		}
+	case strings.TrimSpace(string(state)) != "true":
+		if err := exec.Command("docker", "start", name).Run(); err != nil {
+			return "", errors.Wrap(err, "failed to start the MQTT broker")
+		}
+	}
+
+	lines, err := exec.OutputLines(exec.Command("docker", "inspect", "-f", `{{(index .NetworkSettings.Networks "kind").IPAddress}}`, name))
+	if err != nil || len(lines) != 1 || lines[0] == "" {
+		return "", fmt.Errorf("failed to get the IP of the MQTT broker: %v", err)
+	}
+	return fmt.Sprintf("tcp://%s:%d", lines[0], mqttBrokerPort), nil
+}
+
+// keadmEventBusSettings are the keadm join --set values of the eventBus
+func (a *Action) keadmEventBusSettings() string {
+	settings := fmt.Sprintf("modules.eventBus.mqttMode=%d", edgecore.MQTTMode(a.MQTTMode))
+	if a.mqttBroker != "" {
+		settings += ",modules.eventBus.mqttServerExternal=" + a.mqttBroker
+	}
+	return settings
+}
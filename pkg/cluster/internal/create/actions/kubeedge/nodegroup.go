@@ -0,0 +1,117 @@
+package kubeedge
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/cluster/shared/create/actions"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"github.com/kubeedge/keink/pkg/cluster/internal/manifest"
+)
+
+// NodeGroupLabelKey labels the edge nodes with their nodeGroup, the NodeGroup
+// objects select their nodes with it
+const NodeGroupLabelKey = "keink.kubeedge.io/node-group"
+
+// the CRDs of the NodeGroup and EdgeApplication API
+var appsCRDs = []string{
+	"apps_v1alpha1_nodegroup.yaml",
+	"apps_v1alpha1_edgeapplication.yaml",
+}
+
+const nodeGroupManifest = `{{ range . }}---
+apiVersion: apps.kubeedge.io/v1alpha1
+kind: NodeGroup
+metadata:
+  name: {{ . }}
+spec:
+  matchLabels:
+    ` + NodeGroupLabelKey + `: {{ . }}
+{{ end }}`
+
+// nodeGroups returns the edge nodes of every nodeGroup of the config
+func (a *Action) nodeGroups() map[string][]string {
+	groups := map[string][]string{}
+	for name, node := range a.EdgeNodes {
+		if node.NodeGroup != "" {
+			groups[node.NodeGroup] = append(groups[node.NodeGroup], name)
+		}
+	}
+	return groups
+}
+
+// setupNodeGroups runs the controllermanager, which implements NodeGroups
+// and EdgeApplications, labels the edge nodes with their nodeGroup and
+// creates a NodeGroup for each of them
+func (a *Action) setupNodeGroups(ctx *actions.ActionContext) error {
+	groups := a.nodeGroups()
+	if len(groups) == 0 {
+		return nil
+	}
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	controlPlane, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+
+	// keadm init installs the CRDs and deploys the controllermanager
+	if !a.ContainerMode {
+		if err := startControllerManager(ctx, controlPlane); err != nil {
+			return err
+		}
+	}
+
+	names := []string{}
+	for group, members := range groups {
+		names = append(names, group)
+		for _, member := range members {
+			cmd := controlPlane.Command("kubectl", "label", "node", member, NodeGroupLabelKey+"="+group, "--overwrite")
+			lines, err := exec.CombinedOutputLines(cmd)
+			ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+			if err != nil {
+				return errors.Wrapf(err, "failed to label node %s", member)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	if err := manifest.RenderAndApply(ctx.Logger, controlPlane, nodeGroupManifest, names); err != nil {
+		return errors.Wrap(err, "failed to create the NodeGroups")
+	}
+	return nil
+}
+
+// startControllerManager installs the apps CRDs and starts the
+// controllermanager built along with the node image
+func startControllerManager(ctx *actions.ActionContext, node nodes.Node) error {
+	if err := node.Command("test", "-x", "/usr/local/bin/controllermanager").Run(); err != nil {
+		return fmt.Errorf("the node image has no controllermanager, rebuild it with `keink build edge-image` from a KubeEdge source that builds controllermanager to use nodeGroup")
+	}
+
+	for _, crd := range appsCRDs {
+		cmd := node.Command("kubectl", "apply", "-f", filepath.Join("/etc/kubeedge/crds/", crd))
+		lines, err := exec.CombinedOutputLines(cmd)
+		ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+		if err != nil {
+			return fmt.Errorf("failed to create CRD %s: %v", crd, err)
+		}
+	}
+
+	cmd := node.Command("bash", "-c", "systemctl daemon-reload && systemctl enable controllermanager && systemctl restart controllermanager")
+	lines, err := exec.CombinedOutputLines(cmd)
+	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		return fmt.Errorf("failed to start controllermanager: %v", err)
+	}
+	return nil
+}
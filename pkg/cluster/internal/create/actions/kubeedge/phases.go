@@ -0,0 +1,141 @@
+package kubeedge
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/cluster/shared/create/actions"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+)
+
+// phaseStateFile records the completed phases on the control-plane node,
+// one phase name per line, so that a retained cluster can be resumed
+const phaseStateFile = "/etc/kubeedge/keink/phases"
+
+// phase is a named step of the KubeEdge bootstrap, every phase must be
+// safe to run again on a node where it has partially run before
+type phase struct {
+	name string
+	run  func(ctx *actions.ActionContext) error
+}
+
+// phases returns the bootstrap phases in the order they run
+func (a *Action) phases() []phase {
+	return []phase{
+		{name: constants.PhaseControlPlaneReady, run: a.waitControlPlaneReady},
+		{name: constants.PhaseKubeProxy, run: a.patchKubeProxy},
+		{name: constants.PhaseCloudCore, run: a.BootstrapCloudCore},
+		{name: constants.PhaseEdgeCore, run: a.BootstrapEdgecore},
+		{name: constants.PhaseNodeGroups, run: a.setupNodeGroups},
+		{name: constants.PhaseEdgeCNI, run: a.setupEdgeCNI},
+	}
+}
+
+// phaseState tracks the completed phases of a cluster
+type phaseState struct {
+	node nodes.Node
+
+	mu   sync.Mutex
+	done map[string]bool
+}
+
+// loadPhaseState reads the completed phases from the control-plane node,
+// a missing state file means nothing has completed yet
+func loadPhaseState(ctx *actions.ActionContext) (*phaseState, error) {
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := node.Command("bash", "-c", fmt.Sprintf("cat %s 2>/dev/null || true", phaseStateFile))
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the KubeEdge phase state")
+	}
+
+	state := &phaseState{
+		node: node,
+		done: make(map[string]bool),
+	}
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			state.done[line] = true
+		}
+	}
+	return state, nil
+}
+
+// completed returns true if the phase (or per node step) has been recorded
+func (s *phaseState) completed(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done[name]
+}
+
+// markCompleted records the phase (or per node step) on the control-plane node
+func (s *phaseState) markCompleted(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cmd := s.node.Command("bash", "-c", fmt.Sprintf("mkdir -p $(dirname %[1]s) && echo %[2]s >> %[1]s", phaseStateFile, name))
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "failed to record phase %s", name)
+	}
+	s.done[name] = true
+	return nil
+}
+
+// recordExposedAddress writes the address the cloudcore ports are exposed at
+// next to the phase state, the cluster options are gone when it is resumed
+func recordExposedAddress(node nodes.Node, address string) error {
+	cmd := node.Command("bash", "-c", fmt.Sprintf("mkdir -p $(dirname %[1]s) && echo %[2]s > %[1]s", constants.ExposedAddressFile, address))
+	if err := cmd.Run(); err != nil {
+		return errors.Wrap(err, "failed to record the exposed cloudcore address")
+	}
+	return nil
+}
+
+// edgeNodeStep is the name recorded once a single edge node has joined
+func edgeNodeStep(node nodes.Node) string {
+	return constants.PhaseEdgeCore + "/" + node.String()
+}
+
+// runPhases runs every phase in order, skipping the phases the user asked
+// to skip and, when resuming, the phases that have already completed
+func (a *Action) runPhases(ctx *actions.ActionContext) error {
+	skip := make(map[string]bool, len(a.SkipPhases))
+	for _, name := range a.SkipPhases {
+		skip[name] = true
+	}
+
+	for _, p := range a.phases() {
+		if skip[p.name] {
+			ctx.Logger.V(0).Infof("Skipping phase %q", p.name)
+			continue
+		}
+		if a.Resume && a.state.completed(p.name) {
+			ctx.Logger.V(0).Infof("Skipping phase %q, already completed", p.name)
+			continue
+		}
+
+		ctx.Logger.V(1).Infof("Running phase %q", p.name)
+		if err := p.run(ctx); err != nil {
+			return errors.Wrapf(err, "phase %q failed", p.name)
+		}
+		if err := a.state.markCompleted(p.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package kubeedge
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/shared/create/actions"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// enableRouter turns on the router module in cloudcore.yaml, it serves the
+// rest endpoints of the Rules on port 9443
+func enableRouter(ctx *actions.ActionContext, node nodes.Node) error {
+	cmd := node.Command("bash", "-c", `sed -i '/^  router:/,/enable:/s/enable: false/enable: true/' /etc/kubeedge/config/cloudcore.yaml`)
+	lines, err := exec.CombinedOutputLines(cmd)
+	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		return fmt.Errorf("failed to enable router: %v", err)
+	}
+	return nil
+}
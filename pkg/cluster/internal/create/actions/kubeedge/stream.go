@@ -0,0 +1,71 @@
+package kubeedge
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/cluster/shared/create/actions"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// the ports of the stream tunnel, the API server connects to edged on the
+// edge node, which is redirected to the cloudcore stream server
+const (
+	edgedPort  = 10350
+	streamPort = 10003
+	tunnelPort = 10004
+)
+
+// enableCloudStream turns on cloudStream in cloudcore.yaml, with a stream
+// certificate valid for the addresses edge nodes and the API server use
+func (a *Action) enableCloudStream(ctx *actions.ActionContext, node nodes.Node) error {
+	ip, _, err := node.IP()
+	if err != nil {
+		return fmt.Errorf("failed to get control-plane IP: %v", err)
+	}
+	ips := []string{ip}
+	if a.AdvertiseAddress != "" {
+		ips = append(ips, a.AdvertiseAddress)
+	}
+
+	if err := generateStreamCerts(node, ips); err != nil {
+		return fmt.Errorf("failed to generate stream certificates: %v", err)
+	}
+
+	cmd := node.Command("bash", "-c", `sed -i '/cloudStream:/{n;s/false/true/;}' /etc/kubeedge/config/cloudcore.yaml`)
+	lines, err := exec.CombinedOutputLines(cmd)
+	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		return fmt.Errorf("failed to enable cloudStream: %v", err)
+	}
+
+	return installStreamRules(ctx, fmt.Sprintf("%s:%d", ip, streamPort))
+}
+
+// installStreamRules redirects the API server requests for the edged port
+// (logs, exec, metrics) to the cloudcore stream server at target, on every
+// control-plane node. This is the rule the cloudcore iptablesManager would
+// install, keink turns the manager off and installs the rule itself
+func installStreamRules(ctx *actions.ActionContext, target string) error {
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return err
+	}
+
+	rule := fmt.Sprintf("OUTPUT -p tcp --dport %d -j DNAT --to-destination %s -m comment --comment keink-stream", edgedPort, target)
+	for _, node := range controlPlanes {
+		cmd := node.Command("bash", "-c", fmt.Sprintf("iptables -t nat -C %[1]s 2>/dev/null || iptables -t nat -A %[1]s", rule))
+		lines, err := exec.CombinedOutputLines(cmd)
+		ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+		if err != nil {
+			return fmt.Errorf("failed to install the stream iptables rule on %s: %v", node, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package mapper
+
+// mapperManifest runs the mapper on every edge node with the /etc/kubeedge
+// directory of the node mounted, where edgecore serves the DMI socket
+// dmi.sock and the mapper serves its own socket for edgecore
+const mapperManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Name }}
+  namespace: kubeedge
+data:
+  config.yaml: |
+    grpc_server:
+      socket_path: /etc/kubeedge/{{ .Protocol }}.sock
+    common:
+      name: {{ .Name }}
+      version: v1.17.0
+      api_version: v1.0.0
+      protocol: {{ .Protocol }}
+      address: 127.0.0.1
+      edgecore_sock: /etc/kubeedge/dmi.sock
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: {{ .Name }}
+  namespace: kubeedge
+  labels:
+    app: {{ .Name }}
+spec:
+  selector:
+    matchLabels:
+      app: {{ .Name }}
+  template:
+    metadata:
+      labels:
+        app: {{ .Name }}
+    spec:
+      hostNetwork: true
+      nodeSelector:
+        node-role.kubernetes.io/edge: ""
+      tolerations:
+      - operator: Exists
+      containers:
+      - name: mapper
+        image: {{ .Image }}
+        imagePullPolicy: IfNotPresent
+        args:
+        - --config-file=/opt/kubeedge/config.yaml
+        volumeMounts:
+        - name: config
+          mountPath: /opt/kubeedge
+        - name: kubeedge
+          mountPath: /etc/kubeedge
+      volumes:
+      - name: config
+        configMap:
+          name: {{ .Name }}
+      - name: kubeedge
+        hostPath:
+          path: /etc/kubeedge
+          type: Directory
+`
@@ -0,0 +1,190 @@
+// Package mapper implements the action installing a device mapper add-on,
+// a mapper-framework mapper talking to the edgecore DMI server
+package mapper
+
+import (
+	"fmt"
+	"go/build"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"github.com/kubeedge/keink/pkg/apis/config/edge"
+	"github.com/kubeedge/keink/pkg/cluster/actions"
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+	"github.com/kubeedge/keink/pkg/cluster/internal/device"
+	"github.com/kubeedge/keink/pkg/cluster/internal/manifest"
+)
+
+// mappersImportPath is where the mapper sources are looked up in GOPATH,
+// a mapper is built from mappers/<protocol> when it has a Dockerfile
+const mappersImportPath = "github.com/kubeedge/mappers-go"
+
+// images are the published mapper images, used when there is no source
+var images = map[string]string{
+	constants.DeviceProtocolModbus: "kubeedge/modbus-mapper:v1.17.0",
+	constants.DeviceProtocolOPCUA:  "kubeedge/opcua-mapper:v1.17.0",
+	constants.DeviceProtocolMQTT:   "kubeedge/mqtt-mapper:v1.17.0",
+}
+
+// checkDevice is the simulated device the mapper is checked against
+const checkDevice = "mapper-check"
+
+// checkTimeout is how long the reported values of the check device are
+// waited for
+const checkTimeout = 5 * time.Minute
+
+// Action deploys the mapper of a protocol to every edge node, and checks
+// that the status of a simulated device shows the values it reports
+type Action struct {
+	protocol string
+	// edgeNodes are the keink settings of the edge nodes, the image is
+	// loaded according to their runtime
+	edgeNodes map[string]edge.Node
+}
+
+// NewAction returns a new action for installing the mapper of protocol
+func NewAction(protocol string, edgeNodes map[string]edge.Node) actions.Action {
+	return &Action{
+		protocol:  protocol,
+		edgeNodes: edgeNodes,
+	}
+}
+
+// Execute runs the action
+func (a *Action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start(fmt.Sprintf("Installing the %s mapper 📟", a.protocol))
+	defer ctx.Status.End(false)
+
+	controlPlane, err := ctx.ControlPlane()
+	if err != nil {
+		return err
+	}
+	edgeNodes, err := ctx.EdgeNodes()
+	if err != nil {
+		return err
+	}
+	if len(edgeNodes) == 0 {
+		return errors.New("the mapper add-on needs an edge node")
+	}
+
+	image, err := a.image(ctx)
+	if err != nil {
+		return err
+	}
+	for _, node := range edgeNodes {
+		if err := a.loadImage(ctx, node, image); err != nil {
+			return err
+		}
+	}
+
+	if err := manifest.RenderAndApply(ctx.Logger, controlPlane, mapperManifest, map[string]string{
+		"Name":     a.protocol + "-mapper",
+		"Protocol": a.protocol,
+		"Image":    image,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to deploy the %s mapper", a.protocol)
+	}
+
+	if err := a.check(ctx, controlPlane, edgeNodes[0]); err != nil {
+		return err
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// image builds the mapper image from the mappers-go source when it is in
+// GOPATH, or returns the published image
+func (a *Action) image(ctx *actions.ActionContext) (string, error) {
+	dir := filepath.Join(build.Default.GOPATH, "src", mappersImportPath, "mappers", a.protocol)
+	if _, err := os.Stat(filepath.Join(dir, "Dockerfile")); err != nil {
+		return images[a.protocol], nil
+	}
+
+	image := fmt.Sprintf("kubeedge/%s-mapper:keink", a.protocol)
+	ctx.Logger.V(1).Infof("Building %s from %s", image, dir)
+	cmd := exec.Command("docker", "build", "-t", image, dir)
+	lines, err := exec.CombinedOutputLines(cmd)
+	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to build the %s mapper", a.protocol)
+	}
+	return image, nil
+}
+
+// loadImage copies the image from the host docker into the runtime of the
+// edge node, like `kind load docker-image`. An image the host doesn't have
+// is pulled by the edge node instead
+func (a *Action) loadImage(ctx *actions.ActionContext, node nodes.Node, image string) error {
+	if err := exec.Command("docker", "image", "inspect", image).Run(); err != nil {
+		return nil
+	}
+
+	var load exec.Cmd
+	switch runtime := a.edgeNodes[node.String()].Runtime; runtime {
+	case "", constants.RuntimeContainerd:
+		load = node.Command("ctr", "--namespace=k8s.io", "images", "import", "--all-platforms", "--digests", "-")
+	case constants.RuntimeDocker:
+		load = node.Command("docker", "load")
+	case constants.RuntimeCRIO:
+		// CRI-O reads the images of containers/storage, which skopeo writes
+		if err := node.Command("bash", "-c", "command -v skopeo").Run(); err != nil {
+			return fmt.Errorf("failed to load %s into %s: the %s runtime needs skopeo in the node image", image, node, runtime)
+		}
+		load = node.Command("bash", "-c", fmt.Sprintf(
+			`f=$(mktemp) && cat > "$f" && skopeo copy "docker-archive:$f" "containers-storage:%s"; rc=$?; rm -f "$f"; exit $rc`, image))
+	default:
+		return fmt.Errorf("failed to load %s into %s: unsupported runtime %q", image, node, runtime)
+	}
+
+	save := exec.Command("docker", "save", image)
+	reader, writer := io.Pipe()
+	save.SetStdout(writer)
+	errCh := make(chan error, 1)
+	go func() {
+		err := save.Run()
+		writer.CloseWithError(err)
+		errCh <- err
+	}()
+
+	if err := load.SetStdin(reader).Run(); err != nil {
+		return errors.Wrapf(err, "failed to load %s into %s", image, node)
+	}
+	if err := <-errCh; err != nil {
+		return errors.Wrapf(err, "failed to save %s", image)
+	}
+	return nil
+}
+
+// check creates a simulated device on the edge node and waits for the
+// mapper to report its values to the Device status
+func (a *Action) check(ctx *actions.ActionContext, controlPlane, node nodes.Node) error {
+	if err := device.Create(ctx.Logger, ctx.Config.Name, controlPlane, node, device.Options{
+		Name:     checkDevice,
+		Protocol: a.protocol,
+	}); err != nil {
+		return err
+	}
+	defer func() {
+		_ = device.Delete(ctx.Logger, ctx.Config.Name, controlPlane, checkDevice)
+	}()
+
+	for start := time.Now(); time.Since(start) < checkTimeout; time.Sleep(5 * time.Second) {
+		cmd := controlPlane.Command("kubectl", "get", "devices.devices.kubeedge.io", checkDevice, "-n", "default",
+			"-o=jsonpath={.status.twins[*].reported.value}")
+		lines, err := exec.OutputLines(cmd)
+		if err == nil && len(lines) > 0 && strings.TrimSpace(lines[0]) != "" {
+			ctx.Logger.V(1).Infof("Device %s reported %s", checkDevice, lines[0])
+			return nil
+		}
+	}
+	return fmt.Errorf("the status of device %s shows no values reported by the %s mapper after %s", checkDevice, a.protocol, checkTimeout)
+}
@@ -0,0 +1,51 @@
+package create
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubeedge/keink/pkg/cluster/actions"
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+	"github.com/kubeedge/keink/pkg/cluster/internal/create/actions/edgemesh"
+	"github.com/kubeedge/keink/pkg/cluster/internal/create/actions/kubeedge"
+	"github.com/kubeedge/keink/pkg/cluster/internal/create/actions/mapper"
+)
+
+// ParseAddon splits an --addon value of the form name[=value]
+func ParseAddon(addon string) (name, value string) {
+	name, value, _ = strings.Cut(addon, "=")
+	return name, value
+}
+
+// hasAddon reports whether the add-on name is requested, with or without
+// a value
+func hasAddon(addons []string, name string) bool {
+	for _, addon := range addons {
+		if addonName, _ := ParseAddon(addon); addonName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// addonActions returns the actions installing the requested add-ons, and
+// turns on the KubeEdge features they depend on
+func addonActions(addons []string, kubeEdgeOpts *kubeedge.Options) ([]actions.Action, error) {
+	addonsToRun := []actions.Action{}
+	for _, addon := range addons {
+		name, value := ParseAddon(addon)
+		switch name {
+		case constants.AddonEdgeMesh:
+			// EdgeMesh watches Services through the edgecore metaServer,
+			// which is served by the cloudcore dynamicController
+			kubeEdgeOpts.EnableDynamicController = true
+			kubeEdgeOpts.EnableStream = true
+			addonsToRun = append(addonsToRun, edgemesh.NewAction())
+		case constants.AddonMapper:
+			addonsToRun = append(addonsToRun, mapper.NewAction(value, kubeEdgeOpts.EdgeNodes))
+		default:
+			return nil, fmt.Errorf("unknown addon %q, must be one of %v", name, constants.Addons)
+		}
+	}
+	return addonsToRun, nil
+}
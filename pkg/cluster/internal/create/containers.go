@@ -0,0 +1,27 @@
+package create
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+)
+
+// DeleteContainers removes the containers keink runs next to the kind nodes
+// of the cluster, such as the device simulators and the MQTT broker, or of
+// every cluster when name is empty
+func DeleteContainers(name string) error {
+	filter := "label=" + constants.ContainerClusterLabelKey
+	if name != "" {
+		filter += "=" + name
+	}
+	containers, err := exec.OutputLines(exec.Command("docker", "ps", "-aq", "--filter", filter))
+	if err != nil {
+		return fmt.Errorf("failed to list the containers of cluster %q: %v", name, err)
+	}
+	if len(containers) == 0 {
+		return nil
+	}
+	return exec.Command("docker", append([]string{"rm", "-f", "-v"}, containers...)...).Run()
+}
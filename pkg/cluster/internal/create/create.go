@@ -2,12 +2,16 @@ package create
 
 import (
 	"sigs.k8s.io/kind/pkg/cluster/shared/create"
-	"sigs.k8s.io/kind/pkg/cluster/shared/create/actions"
+	kindactions "sigs.k8s.io/kind/pkg/cluster/shared/create/actions"
 	"sigs.k8s.io/kind/pkg/cluster/shared/delete"
 	"sigs.k8s.io/kind/pkg/cluster/shared/providers"
+	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/log"
 	"sigs.k8s.io/kind/pkg/shared/cli"
 
+	"github.com/kubeedge/keink/pkg/apis/config/edge"
+	"github.com/kubeedge/keink/pkg/cluster/actions"
+	"github.com/kubeedge/keink/pkg/cluster/constants"
 	"github.com/kubeedge/keink/pkg/cluster/internal/create/actions/kubeedge"
 )
 
@@ -17,6 +21,40 @@ type ClusterOptions struct {
 
 	AdvertiseAddress string
 	ContainerMode    bool
+
+	// ExposeCloudCore maps the cloudcore ports of the control-plane to the
+	// host, ExposedAddress is the host address they are reached at
+	ExposeCloudCore bool
+	ExposedAddress  string
+
+	// CACert and CAKey are a PEM encoded CA for cloudcore to use
+	CACert []byte
+	CAKey  []byte
+
+	// MQTTMode is the MQTT broker of the edgecore eventBus
+	MQTTMode string
+
+	// EnableRouter turns on the cloudcore router and the edgecore serviceBus
+	EnableRouter bool
+
+	// Resume continues the KubeEdge bootstrap of an existing cluster
+	// from the first phase that has not completed
+	Resume bool
+	// SkipPhases lists the KubeEdge bootstrap phases to leave out
+	SkipPhases []string
+
+	// EdgeCNI is the pod network setup of the edge nodes
+	EdgeCNI string
+	// EnableStream turns on the cloudStream/edgeStream tunnel, so that
+	// kubectl logs/exec work against pods on edge nodes
+	EnableStream bool
+	// Addons are the add-ons to install, each of the form name[=value]
+	Addons []string
+	// Actions are extension steps run after the KubeEdge bootstrap
+	Actions []actions.Action
+	// NodeSettings are the keink specific fields of the config node
+	// entries, in the order of the entries
+	NodeSettings []edge.Node
 }
 
 // Cluster creates a cluster
@@ -24,20 +62,101 @@ func Cluster(logger log.Logger, p providers.Provider, opts *ClusterOptions) erro
 	// setup a status object to show progress to the user
 	status := cli.StatusForLogger(logger)
 
-	actionsToRun := []actions.Action{
-		kubeedge.NewAction(opts.AdvertiseAddress, opts.ContainerMode), // run kubeedge install
+	kubeEdgeOpts, addonsToRun, err := kubeEdgeOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	actionsToRun := []kindactions.Action{
+		kubeedge.NewAction(kubeEdgeOpts), // run kubeedge install
 	}
 
-	actionsContext := actions.NewActionContext(logger, status, p, opts.Config)
+	actionsContext := kindactions.NewActionContext(logger, status, p, opts.Config)
+
+	// cleanup the cluster on failure unless asked to retain it
+	fail := func(err error) error {
+		if !opts.Retain {
+			_ = delete.Cluster(logger, p, opts.Config.Name, opts.KubeconfigPath)
+			_ = DeleteContainers(opts.Config.Name)
+		}
+		return err
+	}
 
 	for _, action := range actionsToRun {
 		if err := action.Execute(actionsContext); err != nil {
-			if !opts.Retain {
-				_ = delete.Cluster(logger, p, opts.Config.Name, opts.KubeconfigPath)
-			}
-			return err
+			return fail(err)
+		}
+	}
+
+	// then the add-ons and the extension actions, in the order they were given
+	extensionContext := actions.NewActionContext(actionsContext)
+	for _, action := range append(addonsToRun, opts.Actions...) {
+		if err := action.Execute(extensionContext); err != nil {
+			return fail(err)
 		}
 	}
 
 	return nil
 }
+
+// Validate checks the KubeEdge options that can be checked before the
+// kind cluster is created
+func Validate(opts *ClusterOptions) error {
+	_, _, err := kubeEdgeOptions(opts)
+	return err
+}
+
+// kubeEdgeOptions returns the options of the kubeedge action and the
+// actions of the add-ons, which may turn on KubeEdge features
+func kubeEdgeOptions(opts *ClusterOptions) (kubeedge.Options, []actions.Action, error) {
+	kubeEdgeOpts := kubeedge.Options{
+		AdvertiseAddress: opts.AdvertiseAddress,
+		ContainerMode:    opts.ContainerMode,
+		ExposedAddress:   opts.ExposedAddress,
+		CACert:           opts.CACert,
+		CAKey:            opts.CAKey,
+		MQTTMode:         opts.MQTTMode,
+		EnableRouter:     opts.EnableRouter,
+		Resume:           opts.Resume,
+		SkipPhases:       opts.SkipPhases,
+		EdgeCNI:          opts.EdgeCNI,
+		EnableStream:     opts.EnableStream,
+		EdgeNodes:        edgeNodeSettings(opts.Config, opts.NodeSettings),
+	}
+
+	// flannel on edge nodes lists nodes through the edgecore metaServer
+	if opts.EdgeCNI == constants.EdgeCNIFlannelEdge {
+		kubeEdgeOpts.EnableDynamicController = true
+	}
+
+	// add-ons may need KubeEdge features, so resolve them first
+	addonsToRun, err := addonActions(opts.Addons, &kubeEdgeOpts)
+	if err != nil {
+		return kubeEdgeOpts, nil, err
+	}
+
+	// keadm deploys cloudcore with the CA it generates
+	if len(opts.CACert) > 0 && opts.ContainerMode {
+		return kubeEdgeOpts, nil, errors.New("a custom CA is not supported in container mode")
+	}
+
+	// keadm runs one cloudcore replica, and the edge nodes reach it through
+	// the NodePorts of the bootstrap control-plane node, not the load balancer
+	if opts.ContainerMode && controlPlaneCount(opts.Config) > 1 {
+		return kubeEdgeOpts, nil, errors.New("container mode is not supported with several control-plane nodes")
+	}
+
+	// the API server would reach the edge nodes through the local cloudcore,
+	// which may not be the one holding the tunnel of the edge node
+	if controlPlaneCount(opts.Config) > 1 {
+		// EdgeMesh turns the tunnel on by itself, name it rather than the
+		// flag the user did not pass
+		if hasAddon(opts.Addons, constants.AddonEdgeMesh) {
+			return kubeEdgeOpts, nil, errors.Errorf("the %s addon needs the cloudStream tunnel, which is not supported with several control-plane nodes", constants.AddonEdgeMesh)
+		}
+		if kubeEdgeOpts.EnableStream {
+			return kubeEdgeOpts, nil, errors.New("--enable-stream is not supported with several control-plane nodes")
+		}
+	}
+	return kubeEdgeOpts, addonsToRun, nil
+}
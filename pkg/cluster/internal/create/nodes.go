@@ -0,0 +1,40 @@
+package create
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kind/pkg/shared/apis/config"
+
+	"github.com/kubeedge/keink/pkg/apis/config/edge"
+)
+
+// edgeNodeSettings maps the keink specific fields of the config node entries
+// to the names of the node containers. kind names the nodes of a role
+// <cluster>-<role>, then <cluster>-<role>2 and so on, in config order
+func edgeNodeSettings(cfg *config.Cluster, settings []edge.Node) map[string]edge.Node {
+	byName := make(map[string]edge.Node, len(settings))
+	counter := map[config.NodeRole]int{}
+	for i, node := range cfg.Nodes {
+		counter[node.Role]++
+		if i >= len(settings) {
+			continue
+		}
+		name := fmt.Sprintf("%s-%s", cfg.Name, node.Role)
+		if counter[node.Role] > 1 {
+			name = fmt.Sprintf("%s%d", name, counter[node.Role])
+		}
+		byName[name] = settings[i]
+	}
+	return byName
+}
+
+// controlPlaneCount returns the number of control-plane nodes of the config
+func controlPlaneCount(cfg *config.Cluster) int {
+	count := 0
+	for _, node := range cfg.Nodes {
+		if node.Role == config.ControlPlaneRole {
+			count++
+		}
+	}
+	return count
+}
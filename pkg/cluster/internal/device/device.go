@@ -0,0 +1,309 @@
+// Package device simulates devices attached to edge nodes, a simulator
+// container per device next to the edge node plus the DeviceModel and
+// Device objects of the KubeEdge device API bound to the node
+package device
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+	"github.com/kubeedge/keink/pkg/cluster/internal/manifest"
+)
+
+// DeviceLabelKey labels the simulator containers and the Device objects
+// with the name of the device
+const DeviceLabelKey = "io.kubeedge.keink.device"
+
+// the docker network kind runs the nodes on
+const kindNetwork = "kind"
+
+// deviceAPIVersion is the version of the device CRDs the node image installs
+const deviceAPIVersion = "devices.kubeedge.io/v1beta1"
+
+// simulator is the container that plays a device of a protocol
+type simulator struct {
+	image string
+	// args are the container arguments, the properties are passed to
+	// simulators that generate values for them
+	args func(properties []string) []string
+	port int
+	// protocolConfig is the configData of the Device protocol, for the
+	// simulator at ip
+	protocolConfig func(ip string, port int) map[string]interface{}
+	// visitorConfig is the configData of the visitor of the i-th property
+	visitorConfig func(device, property string, i int) map[string]interface{}
+}
+
+var simulators = map[string]simulator{
+	constants.DeviceProtocolModbus: {
+		image: "oitc/modbus-server:1.2.0",
+		args:  func([]string) []string { return nil },
+		port:  5020,
+		protocolConfig: func(ip string, port int) map[string]interface{} {
+			return map[string]interface{}{"ip": ip, "port": port, "slaveID": 1}
+		},
+		visitorConfig: func(_, _ string, i int) map[string]interface{} {
+			return map[string]interface{}{"register": "HoldingRegister", "offset": i, "limit": 1, "scale": 1}
+		},
+	},
+	constants.DeviceProtocolOPCUA: {
+		image: "mcr.microsoft.com/iotedge/opc-plc:2.12.29",
+		args: func([]string) []string {
+			return []string{"--autoaccept", "--unsecuretransport", "--portnum=50000"}
+		},
+		port: 50000,
+		protocolConfig: func(ip string, port int) map[string]interface{} {
+			return map[string]interface{}{"url": fmt.Sprintf("opc.tcp://%s:%d", ip, port)}
+		},
+		// opc-plc simulates changing values in these nodes
+		visitorConfig: func(_, _ string, i int) map[string]interface{} {
+			plcNodes := []string{"StepUp", "AlternatingBoolean", "RandomSignedInt32", "RandomUnsignedInt32"}
+			return map[string]interface{}{"nodeID": "ns=3;s=" + plcNodes[i%len(plcNodes)]}
+		},
+	},
+	constants.DeviceProtocolMQTT: {
+		image: "eclipse-mosquitto:2.0",
+		// mosquitto with anonymous access, and a random value published to
+		// the topic of every property every 5 seconds
+		args: func(properties []string) []string {
+			topics := []string{}
+			for _, p := range properties {
+				topics = append(topics, mqttTopic("$DEVICE", p))
+			}
+			script := fmt.Sprintf(`printf "listener 1883\nallow_anonymous true\n" > /tmp/mosquitto.conf
+mosquitto -c /tmp/mosquitto.conf -d
+while true; do
+  for topic in %s; do mosquitto_pub -t "$topic" -m "$((RANDOM %% 100))"; done
+  sleep 5
+done`, strings.Join(topics, " "))
+			return []string{"sh", "-c", script}
+		},
+		port: 1883,
+		protocolConfig: func(ip string, port int) map[string]interface{} {
+			return map[string]interface{}{"brokerURL": fmt.Sprintf("tcp://%s:%d", ip, port)}
+		},
+		visitorConfig: func(device, property string, _ int) map[string]interface{} {
+			return map[string]interface{}{"topic": mqttTopic(device, property)}
+		},
+	},
+}
+
+func mqttTopic(device, property string) string {
+	return fmt.Sprintf("keink/%s/%s", device, property)
+}
+
+// Options are the options of a simulated device
+type Options struct {
+	// Name of the Device, and of its simulator container
+	Name string
+	// Protocol is one of constants.DeviceProtocols
+	Protocol string
+	// ModelFile is a DeviceModel manifest, a model with a single property
+	// is created when it is empty
+	ModelFile string
+}
+
+// model is the part of a DeviceModel keink reads
+type model struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Protocol   string `json:"protocol"`
+		Properties []struct {
+			Name string `json:"name"`
+		} `json:"properties"`
+	} `json:"spec"`
+}
+
+// Create runs the simulator of the device on the kind network and creates
+// its DeviceModel and a Device bound to the edge node
+func Create(logger log.Logger, cluster string, controlPlane, edgeNode nodes.Node, opts Options) error {
+	sim, ok := simulators[opts.Protocol]
+	if !ok {
+		return fmt.Errorf("unknown device protocol %q, must be one of %v", opts.Protocol, constants.DeviceProtocols)
+	}
+
+	rawModel, m, err := loadModel(opts)
+	if err != nil {
+		return err
+	}
+	properties := []string{}
+	for _, p := range m.Spec.Properties {
+		properties = append(properties, p.Name)
+	}
+
+	ip, err := runSimulator(logger, cluster, sim, opts.Name, properties)
+	if err != nil {
+		return err
+	}
+
+	device := map[string]interface{}{
+		"apiVersion": deviceAPIVersion,
+		"kind":       "Device",
+		"metadata": map[string]interface{}{
+			"name":      opts.Name,
+			"namespace": "default",
+			"labels":    map[string]string{DeviceLabelKey: opts.Name},
+		},
+		"spec": map[string]interface{}{
+			"deviceModelRef": map[string]string{"name": m.Metadata.Name},
+			"nodeName":       edgeNode.String(),
+			"protocol": map[string]interface{}{
+				"protocolName": opts.Protocol,
+				"configData":   sim.protocolConfig(ip, sim.port),
+			},
+			"properties": deviceProperties(sim, opts, properties),
+		},
+	}
+	rawDevice, err := yaml.Marshal(device)
+	if err != nil {
+		removeSimulator(logger, cluster, opts.Name)
+		return err
+	}
+
+	if err := manifest.Apply(logger, controlPlane, string(rawModel)+"\n---\n"+string(rawDevice)); err != nil {
+		// the simulator would block creating the device again with its name
+		removeSimulator(logger, cluster, opts.Name)
+		return errors.Wrapf(err, "failed to create device %s", opts.Name)
+	}
+	logger.V(0).Infof("Device %s (%s at %s:%d) bound to %s", opts.Name, opts.Protocol, ip, sim.port, edgeNode)
+	return nil
+}
+
+func deviceProperties(sim simulator, opts Options, properties []string) []map[string]interface{} {
+	result := []map[string]interface{}{}
+	for i, p := range properties {
+		result = append(result, map[string]interface{}{
+			"name":          p,
+			"collectCycle":  10000,
+			"reportCycle":   10000,
+			"reportToCloud": true,
+			"desired":       map[string]string{"value": ""},
+			"visitors": map[string]interface{}{
+				"protocolName": opts.Protocol,
+				"configData":   sim.visitorConfig(opts.Name, p, i),
+			},
+		})
+	}
+	return result
+}
+
+// loadModel reads the DeviceModel manifest of the options, or builds the
+// default one
+func loadModel(opts Options) ([]byte, *model, error) {
+	raw := []byte(fmt.Sprintf(`apiVersion: %s
+kind: DeviceModel
+metadata:
+  name: %s-model
+  namespace: default
+spec:
+  protocol: %s
+  properties:
+  - name: value
+    type: INT
+    accessMode: ReadWrite
+`, deviceAPIVersion, opts.Name, opts.Protocol))
+	if opts.ModelFile != "" {
+		var err error
+		if raw, err = os.ReadFile(opts.ModelFile); err != nil {
+			return nil, nil, errors.Wrap(err, "failed to read the device model")
+		}
+	}
+
+	m := &model{}
+	if err := yaml.Unmarshal(raw, m); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse the device model")
+	}
+	if m.Kind != "DeviceModel" || m.Metadata.Name == "" {
+		return nil, nil, errors.New("the device model must be a named DeviceModel")
+	}
+	if m.Spec.Protocol != "" && m.Spec.Protocol != opts.Protocol {
+		return nil, nil, fmt.Errorf("the device model is for protocol %q, not %q", m.Spec.Protocol, opts.Protocol)
+	}
+	if len(m.Spec.Properties) == 0 {
+		return nil, nil, errors.New("the device model has no properties")
+	}
+	return raw, m, nil
+}
+
+// runSimulator starts the simulator container and returns its IP on the
+// kind network, which the edge nodes are attached to
+func runSimulator(logger log.Logger, cluster string, sim simulator, name string, properties []string) (string, error) {
+	container := ContainerName(cluster, name)
+	args := []string{"run", "-d",
+		"--name", container,
+		"--hostname", name,
+		"--network", kindNetwork,
+		"--restart", "unless-stopped",
+		"--label", constants.ContainerClusterLabelKey + "=" + cluster,
+		"--label", DeviceLabelKey + "=" + name,
+		"--env", "DEVICE=" + name,
+		sim.image,
+	}
+	args = append(args, sim.args(properties)...)
+	lines, err := exec.CombinedOutputLines(exec.Command("docker", args...))
+	logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to run the simulator of device %s", name)
+	}
+
+	out, err := exec.OutputLines(exec.Command("docker", "inspect", "-f",
+		fmt.Sprintf("{{(index .NetworkSettings.Networks %q).IPAddress}}", kindNetwork), container))
+	if err != nil || len(out) != 1 || out[0] == "" {
+		removeSimulator(logger, cluster, name)
+		return "", fmt.Errorf("failed to get the IP of the simulator of device %s: %v", name, err)
+	}
+	return out[0], nil
+}
+
+// removeSimulator removes the simulator container of a device that could not
+// be created
+func removeSimulator(logger log.Logger, cluster, name string) {
+	if err := exec.Command("docker", "rm", "-f", "-v", ContainerName(cluster, name)).Run(); err != nil {
+		logger.Warnf("failed to remove the simulator of device %s: %v", name, err)
+	}
+}
+
+// ContainerName is the name of the simulator container of a device
+func ContainerName(cluster, device string) string {
+	return fmt.Sprintf("%s-device-%s", cluster, device)
+}
+
+// Delete removes the simulator containers of the cluster, or only the one
+// of device when it is set, and the matching Device objects
+func Delete(logger log.Logger, cluster string, controlPlane nodes.Node, device string) error {
+	filter := "label=" + constants.ContainerClusterLabelKey + "=" + cluster
+	selector := DeviceLabelKey
+	if device != "" {
+		selector = DeviceLabelKey + "=" + device
+	}
+
+	if controlPlane != nil {
+		cmd := controlPlane.Command("kubectl", "delete", "devices.devices.kubeedge.io", "-n", "default", "-l", selector, "--ignore-not-found")
+		lines, err := exec.CombinedOutputLines(cmd)
+		logger.V(3).Info(strings.Join(lines, "\n"))
+		if err != nil {
+			return errors.Wrap(err, "failed to delete the devices")
+		}
+	}
+
+	containers, err := exec.OutputLines(exec.Command("docker", "ps", "-aq", "--filter", filter, "--filter", "label="+selector))
+	if err != nil {
+		return errors.Wrap(err, "failed to list the device simulators")
+	}
+	if len(containers) == 0 {
+		return nil
+	}
+	return exec.Command("docker", append([]string{"rm", "-f", "-v"}, containers...)...).Run()
+}
@@ -0,0 +1,105 @@
+package edgecore
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+// the cgroup drivers of the container runtimes and edgecore
+const (
+	cgroupDriverSystemd  = "systemd"
+	cgroupDriverCgroupfs = "cgroupfs"
+)
+
+// CgroupDriver detects the cgroup driver the container runtime of the node
+// uses, edgecore must be configured with the same one or it crash loops
+func CgroupDriver(logger log.Logger, node nodes.Node, rt Runtime) (string, error) {
+	lines, err := exec.OutputLines(node.Command("bash", "-c", rt.cgroupDriverCmd))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to detect the cgroup driver of the runtime on %s", node)
+	}
+	driver := ""
+	if len(lines) > 0 {
+		driver = strings.TrimSpace(lines[0])
+	}
+	if driver != cgroupDriverSystemd && driver != cgroupDriverCgroupfs {
+		return "", fmt.Errorf("unknown cgroup driver %q of the runtime on %s", driver, node)
+	}
+
+	version, err := cgroupVersion(node)
+	if err != nil {
+		return "", err
+	}
+	logger.V(1).Infof("Runtime on %s uses the %s cgroup driver on cgroup v%d", node, driver, version)
+
+	// the systemd driver needs the systemd hierarchy, which cgroup v1
+	// hosts only mount when systemd manages the cgroups
+	if driver == cgroupDriverSystemd && version == 1 {
+		if err := node.Command("test", "-d", "/sys/fs/cgroup/systemd").Run(); err != nil {
+			return "", fmt.Errorf("runtime on %s uses the systemd cgroup driver, but the cgroup v1 host has no systemd hierarchy", node)
+		}
+	}
+	return driver, nil
+}
+
+// cgroupVersion returns the cgroup version of the host, as seen in the node
+func cgroupVersion(node nodes.Node) (int, error) {
+	lines, err := exec.OutputLines(node.Command("stat", "-fc", "%T", "/sys/fs/cgroup/"))
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to detect the cgroup version on %s", node)
+	}
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "cgroup2fs" {
+		return 2, nil
+	}
+	return 1, nil
+}
+
+// edgeCoreCgroupConfig is the part of the edgecore config holding the
+// cgroup driver
+type edgeCoreCgroupConfig struct {
+	Modules struct {
+		Edged struct {
+			TailoredKubeletConfig struct {
+				CgroupDriver string `json:"cgroupDriver"`
+			} `json:"tailoredKubeletConfig"`
+		} `json:"edged"`
+	} `json:"modules"`
+}
+
+// CheckCgroupDriver reports a mismatch between the cgroup driver in
+// the edgecore config and the one the running container runtime reports
+func CheckCgroupDriver(node nodes.Node, rt Runtime) error {
+	raw, err := exec.Output(node.Command("cat", configFile))
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the edgecore config of %s", node)
+	}
+	c := &edgeCoreCgroupConfig{}
+	if err := yaml.Unmarshal(raw, c); err != nil {
+		return errors.Wrapf(err, "failed to parse the edgecore config of %s", node)
+	}
+	return CheckRuntimeCgroupDriver(node, rt, c.Modules.Edged.TailoredKubeletConfig.CgroupDriver)
+}
+
+// CheckRuntimeCgroupDriver reports a mismatch between the cgroup driver
+// edgecore is configured with and the one the running container runtime
+// reports, for join paths that pass the driver before writing the config
+func CheckRuntimeCgroupDriver(node nodes.Node, rt Runtime, configured string) error {
+	lines, err := exec.OutputLines(node.Command("bash", "-c", rt.runningCgroupDriverCmd))
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the cgroup driver of the running runtime on %s", node)
+	}
+	running := ""
+	if len(lines) > 0 {
+		running = strings.TrimSpace(lines[0])
+	}
+	if configured != running {
+		return fmt.Errorf("cgroup driver mismatch on %s: edgecore is configured with %q, the running container runtime uses %q", node, configured, running)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+// Package edgecore configures and starts edgecore in edge node containers,
+// for the edge nodes of a kind cluster and for standalone ones alike
+package edgecore
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+)
+
+// configFile is the edgecore config of the edge nodes
+const configFile = "/etc/kubeedge/config/edgecore.yaml"
+
+// HTTPSPort is the port of the cloudhub https server edgecore requests its
+// certificate from, unless it is exposed at another one such as a NodePort
+const HTTPSPort = 10002
+
+// mqttModes are the eventBus mqttMode values of edgecore: 0 is the broker
+// built into edgecore, 2 an external broker, and 1 both of them
+var mqttModes = map[string]int{
+	constants.MQTTModeInternal: 0,
+	constants.MQTTModeBoth:     1,
+	constants.MQTTModeExternal: 2,
+}
+
+// MQTTMode returns the eventBus mqttMode of the --mqtt-mode value, the
+// built-in broker by default
+func MQTTMode(name string) int {
+	if mode, ok := mqttModes[name]; ok {
+		return mode
+	}
+	return mqttModes[constants.MQTTModeInternal]
+}
+
+// Config holds the settings keink changes in the default edgecore config
+type Config struct {
+	// CloudCore is the ip:port of the cloudhub websocket server
+	CloudCore string
+	// HTTPSPort is the port of the cloudhub https server on the host of
+	// CloudCore, which the certificate is requested from, HTTPSPort when 0
+	HTTPSPort int
+	// Token is the token of the cloudcore
+	Token string
+	// Runtime is the container runtime edgecore runs pods with, and
+	// CgroupDriver the cgroup driver it uses
+	Runtime      Runtime
+	CgroupDriver string
+	// MQTTMode is the eventBus mqttMode, MQTTBroker the external broker of
+	// the modes using one
+	MQTTMode   int
+	MQTTBroker string
+	// EdgeStreamServer is the ip:port of the cloudcore tunnel, edgeStream
+	// stays off when it is empty
+	EdgeStreamServer string
+	// EnableServiceBus turns on the serviceBus, which delivers the messages
+	// of the Rules with a servicebus endpoint
+	EnableServiceBus bool
+}
+
+// Configure writes the edgecore config of the node, the default config
+// of edgecore with the settings of cfg
+func Configure(logger log.Logger, node nodes.Node, cfg Config) error {
+	host, _, err := net.SplitHostPort(cfg.CloudCore)
+	if err != nil {
+		return errors.Wrapf(err, "invalid cloudcore address %q, must be ip:port", cfg.CloudCore)
+	}
+
+	// generate config
+	if err := run(logger, node, "mkdir -p /etc/kubeedge/config && edgecore --defaultconfig > "+configFile); err != nil {
+		return fmt.Errorf("failed to generate edgecore config: %v", err)
+	}
+
+	if err := sed(logger, node, `-e "/metaServer:/{n;n;s/false/true/;}"`); err != nil {
+		return fmt.Errorf("failed to modify metaServer: %v", err)
+	}
+
+	if err := sed(logger, node, fmt.Sprintf(`-e "s|cgroupDriver: .*|cgroupDriver: %s|g"`, cfg.CgroupDriver)); err != nil {
+		return fmt.Errorf("failed to modify cgroupDriver: %v", err)
+	}
+
+	if err := sed(logger, node, fmt.Sprintf(`-e "s|imageServiceEndpoint: .*|imageServiceEndpoint: %s|g"`, cfg.Runtime.Endpoint)); err != nil {
+		return fmt.Errorf("failed to modify imageServiceEndpoint to remote: %v", err)
+	}
+
+	if err := sed(logger, node, fmt.Sprintf(`-e "s|containerRuntimeEndpoint: .*|containerRuntimeEndpoint: %s|g"`, cfg.Runtime.Endpoint)); err != nil {
+		return fmt.Errorf("failed to modify containerRuntimeEndpoint to remote: %v", err)
+	}
+
+	httpsPort := cfg.HTTPSPort
+	if httpsPort == 0 {
+		httpsPort = HTTPSPort
+	}
+	// modify edgeHub.httpServer websocket.server ip cloudcore ip or control-plane ip
+	if err := sed(logger, node, fmt.Sprintf(`-e "s|httpServer: .*|httpServer: https://%s:%d|g"`, host, httpsPort)); err != nil {
+		return fmt.Errorf("failed to modify httpServer: %v", err)
+	}
+	if err := sed(logger, node, fmt.Sprintf(`-e "s|server: .*10000|server: %s|g"`, cfg.CloudCore)); err != nil {
+		return fmt.Errorf("failed to modify websocket server: %v", err)
+	}
+
+	if cfg.EdgeStreamServer != "" {
+		if err := sed(logger, node, `-e "/edgeStream:/{n;s/false/true/;}"`); err != nil {
+			return fmt.Errorf("failed to enable edgeStream: %v", err)
+		}
+		if err := sed(logger, node, fmt.Sprintf(`-e "s|server: .*10004|server: %s|g"`, cfg.EdgeStreamServer)); err != nil {
+			return fmt.Errorf("failed to modify edgeStream server: %v", err)
+		}
+	}
+
+	eventBus := fmt.Sprintf(`-e "s|mqttMode: .*|mqttMode: %d|g"`, cfg.MQTTMode)
+	if cfg.MQTTBroker != "" {
+		eventBus += fmt.Sprintf(` -e "s|mqttServerExternal: .*|mqttServerExternal: %s|g"`, cfg.MQTTBroker)
+	}
+	if err := sed(logger, node, eventBus); err != nil {
+		return fmt.Errorf("failed to modify mqttMode: %v", err)
+	}
+
+	if cfg.EnableServiceBus {
+		if err := sed(logger, node, `-e "/^  serviceBus:/,/enable:/s/enable: false/enable: true/"`); err != nil {
+			return fmt.Errorf("failed to enable serviceBus: %v", err)
+		}
+	}
+
+	if err := sed(logger, node, `-e "s|/tmp/etc/resolv|/etc/resolv|g"`); err != nil {
+		return fmt.Errorf("failed to modify resolv: %v", err)
+	}
+
+	if err := sed(logger, node, fmt.Sprintf(`-e "s|token: .*|token: %s|g"`, cfg.Token)); err != nil {
+		return fmt.Errorf("failed to modify token: %v", err)
+	}
+	return nil
+}
+
+// Start (re)starts edgecore with its current config
+func Start(logger log.Logger, node nodes.Node) error {
+	if err := run(logger, node, "systemctl daemon-reload && systemctl enable edgecore && systemctl restart edgecore"); err != nil {
+		return errors.Wrapf(err, "failed to start edgecore on %s", node)
+	}
+	return nil
+}
+
+// WaitConnected waits for edgecore on the node to hold a connection to the
+// cloudhub websocket server at cloudCore
+func WaitConnected(node nodes.Node, cloudCore string, timeout time.Duration) error {
+	_, port, err := net.SplitHostPort(cloudCore)
+	if err != nil {
+		return errors.Wrapf(err, "invalid cloudcore address %q, must be ip:port", cloudCore)
+	}
+	script := fmt.Sprintf("ss -Htn state established '( dport = :%s )' | wc -l", port)
+	for start := time.Now(); time.Since(start) < timeout; time.Sleep(2 * time.Second) {
+		lines, err := exec.OutputLines(node.Command("bash", "-c", script))
+		if err == nil && len(lines) > 0 && strings.TrimSpace(lines[0]) != "0" {
+			return nil
+		}
+	}
+	return fmt.Errorf("edgecore on %s did not connect to cloudcore %s within %s", node, cloudCore, timeout)
+}
+
+// edgeHubConfig is the part of the edgecore config holding the cloudhub
+// websocket server
+type edgeHubConfig struct {
+	Modules struct {
+		EdgeHub struct {
+			WebSocket struct {
+				Server string `json:"server"`
+			} `json:"websocket"`
+		} `json:"edgeHub"`
+	} `json:"modules"`
+}
+
+// CloudHubServer returns the ip:port of the cloudhub websocket server in the
+// edgecore config of the node, as Configure or keadm join wrote it
+func CloudHubServer(node nodes.Node) (string, error) {
+	raw, err := exec.Output(node.Command("cat", configFile))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read the edgecore config of %s", node)
+	}
+	c := &edgeHubConfig{}
+	if err := yaml.Unmarshal(raw, c); err != nil {
+		return "", errors.Wrapf(err, "failed to parse the edgecore config of %s", node)
+	}
+	if c.Modules.EdgeHub.WebSocket.Server == "" {
+		return "", fmt.Errorf("no cloudhub server in the edgecore config of %s", node)
+	}
+	return c.Modules.EdgeHub.WebSocket.Server, nil
+}
+
+// sed edits the edgecore config of the node with the sed expressions
+func sed(logger log.Logger, node nodes.Node, expressions string) error {
+	return run(logger, node, fmt.Sprintf("sed -i %s %s", expressions, configFile))
+}
+
+func run(logger log.Logger, node nodes.Node, script string) error {
+	lines, err := exec.CombinedOutputLines(node.Command("bash", "-c", script))
+	logger.V(3).Info(strings.Join(lines, "\n"))
+	return err
+}
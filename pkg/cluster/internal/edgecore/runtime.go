@@ -0,0 +1,80 @@
+package edgecore
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+)
+
+// Runtime is how edgecore talks to a container runtime of the node image
+type Runtime struct {
+	// Endpoint is the CRI socket, used for both the runtime and image services
+	Endpoint string
+	// cgroupDriverCmd prints the cgroup driver the runtime is configured with
+	cgroupDriverCmd string
+	// runningCgroupDriverCmd prints the cgroup driver the running runtime
+	// reports, through its CRI or its daemon
+	runningCgroupDriverCmd string
+	// binary must exist in the node image for the runtime to be usable
+	binary string
+	// services are the systemd units started before edgecore
+	services []string
+}
+
+// runtimes are the runtimes `keink build edge-image --runtime` installs
+var runtimes = map[string]Runtime{
+	constants.RuntimeContainerd: {
+		Endpoint:        "unix:///var/run/containerd/containerd.sock",
+		cgroupDriverCmd: `containerd config dump | grep -q "SystemdCgroup = true" && echo systemd || echo cgroupfs`,
+		// the CRI info of containerd holds the options of the runc runtime
+		runningCgroupDriverCmd: `crictl --runtime-endpoint unix:///var/run/containerd/containerd.sock info | grep -q '"SystemdCgroup": true' && echo systemd || echo cgroupfs`,
+		binary:                 "containerd",
+		// enabled in the kind node image already
+		services: []string{"containerd"},
+	},
+	constants.RuntimeCRIO: {
+		Endpoint:        "unix:///var/run/crio/crio.sock",
+		cgroupDriverCmd: `crio config 2>/dev/null | sed -n 's/^\s*cgroup_manager = "\(.*\)"/\1/p'`,
+		// crio status asks the running daemon for its config
+		runningCgroupDriverCmd: `crio status config 2>/dev/null | sed -n 's/^\s*cgroup_manager = "\(.*\)"/\1/p'`,
+		binary:                 "crio",
+		services:               []string{"crio"},
+	},
+	constants.RuntimeDocker: {
+		Endpoint:               "unix:///var/run/cri-dockerd.sock",
+		cgroupDriverCmd:        `docker info --format "{{.CgroupDriver}}"`,
+		runningCgroupDriverCmd: `docker info --format "{{.CgroupDriver}}"`,
+		binary:                 "cri-dockerd",
+		services:               []string{"docker", "cri-docker.socket", "cri-docker"},
+	},
+}
+
+// StartRuntime starts the container runtime name of the edge node, containerd
+// when it is empty, and returns it
+func StartRuntime(logger log.Logger, node nodes.Node, name string) (Runtime, error) {
+	if name == "" {
+		name = constants.RuntimeContainerd
+	}
+	rt, ok := runtimes[name]
+	if !ok {
+		return rt, fmt.Errorf("unknown runtime %q of %s, must be one of %v", name, node, constants.Runtimes)
+	}
+
+	if err := node.Command("bash", "-c", "command -v "+rt.binary).Run(); err != nil {
+		return rt, fmt.Errorf("runtime %q of %s is not installed in the node image, build it with `keink build edge-image --runtime %s`", name, node, name)
+	}
+
+	cmd := node.Command("bash", "-c", "systemctl daemon-reload && systemctl enable --now "+strings.Join(rt.services, " "))
+	lines, err := exec.CombinedOutputLines(cmd)
+	logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		return rt, errors.Wrapf(err, "failed to start runtime %q on %s", name, node)
+	}
+	return rt, nil
+}
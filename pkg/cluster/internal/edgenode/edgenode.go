@@ -0,0 +1,106 @@
+// Package edgenode simulates power loss, outages and crashes of edge nodes
+package edgenode
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// Stop stops edgecore, the node goes offline but keeps its data
+func Stop(node nodes.Node) error {
+	if err := node.Command("systemctl", "stop", "edgecore").Run(); err != nil {
+		return errors.Wrapf(err, "failed to stop edgecore on %s", node)
+	}
+	return nil
+}
+
+// Start starts edgecore again after Stop
+func Start(node nodes.Node) error {
+	if err := node.Command("systemctl", "start", "edgecore").Run(); err != nil {
+		return errors.Wrapf(err, "failed to start edgecore on %s", node)
+	}
+	return nil
+}
+
+// Pause freezes the whole node container, like a power loss
+func Pause(node nodes.Node) error {
+	if err := exec.Command("docker", "pause", node.String()).Run(); err != nil {
+		return errors.Wrapf(err, "failed to pause %s", node)
+	}
+	return nil
+}
+
+// Unpause resumes the node container after Pause
+func Unpause(node nodes.Node) error {
+	if err := exec.Command("docker", "unpause", node.String()).Run(); err != nil {
+		return errors.Wrapf(err, "failed to unpause %s", node)
+	}
+	return nil
+}
+
+// Crash kills edgecore with SIGKILL and waits for systemd to restart it,
+// which happens after the RestartSec of edgecore.service
+func Crash(node nodes.Node, timeout time.Duration) error {
+	pid, err := mainPID(node)
+	if err != nil {
+		return err
+	}
+	if err := node.Command("systemctl", "kill", "--signal=SIGKILL", "edgecore").Run(); err != nil {
+		return errors.Wrapf(err, "failed to kill edgecore on %s", node)
+	}
+
+	for start := time.Now(); time.Since(start) < timeout; time.Sleep(time.Second) {
+		restarted, err := mainPID(node)
+		if err != nil {
+			return err
+		}
+		if restarted != "0" && restarted != pid {
+			return nil
+		}
+	}
+	return fmt.Errorf("edgecore on %s was not restarted after %s", node, timeout)
+}
+
+// mainPID returns the pid of edgecore, 0 when it isn't running
+func mainPID(node nodes.Node) (string, error) {
+	cmd := node.Command("systemctl", "show", "--property=MainPID", "--value", "edgecore")
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get the edgecore pid on %s", node)
+	}
+	if len(lines) == 0 {
+		return "0", nil
+	}
+	return strings.TrimSpace(lines[0]), nil
+}
+
+// WaitForReady waits until the Ready condition of the node, as seen by the
+// API server, is True when ready is set and not True otherwise
+func WaitForReady(controlPlane nodes.Node, nodeName string, ready bool, timeout time.Duration) error {
+	want := "Ready"
+	if !ready {
+		want = "NotReady"
+	}
+
+	var status string
+	for start := time.Now(); time.Since(start) < timeout; time.Sleep(2 * time.Second) {
+		cmd := controlPlane.Command("kubectl", "get", "node", nodeName, `-o=jsonpath={.status.conditions[?(@.type=="Ready")].status}`)
+		lines, err := exec.OutputLines(cmd)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get node %s", nodeName)
+		}
+		status = ""
+		if len(lines) > 0 {
+			status = strings.TrimSpace(lines[0])
+		}
+		if (status == "True") == ready {
+			return nil
+		}
+	}
+	return fmt.Errorf("node %s is not %s after %s, Ready condition is %q", nodeName, want, timeout, status)
+}
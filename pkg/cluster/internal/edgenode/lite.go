@@ -0,0 +1,142 @@
+package edgenode
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// liteRole is the kind role label value of the lite edge nodes, they are not
+// kind nodes, the edge node label is left off so that the keink commands
+// running systemctl in edge nodes skip them
+const liteRole = "lite-edge-node"
+
+// LiteNodeLabelKey is the Node label of the lite edge nodes
+const LiteNodeLabelKey = "keink.kubeedge.io/lite"
+
+// LiteState is the edgecore state of running lite edge nodes, which run
+// edgemark as their entrypoint instead of systemd and edgecore
+const LiteState = "lite (edgemark, no systemd/edgecore)"
+
+// edgemark is the hollow edgecore of KubeEdge, edgecore with a fake CRI and
+// no kubelet runtime, the node image ships it next to edgecore
+const edgemark = "/usr/local/bin/edgemark"
+
+// liteBatch is the number of lite edge nodes started at once
+const liteBatch = 20
+
+// createLite runs the lite edge nodes of the options, containers of the
+// node image running only edgemark, which registers a Node with cloudcore
+// and syncs its pods without running them
+func createLite(logger log.Logger, name, cloudCoreHost string, opts StandaloneOptions) ([]string, error) {
+	if err := checkEdgemark(opts); err != nil {
+		return nil, err
+	}
+
+	existing, err := ListLite(name)
+	if err != nil {
+		return nil, err
+	}
+	taken := map[string]bool{}
+	for _, node := range existing {
+		taken[node] = true
+	}
+
+	names := []string{}
+	for i := 1; len(names) < opts.Count; i++ {
+		nodeName := fmt.Sprintf("%s-%s", name, liteRole)
+		if i > 1 {
+			nodeName = fmt.Sprintf("%s%d", nodeName, i)
+		}
+		if !taken[nodeName] {
+			names = append(names, nodeName)
+		}
+	}
+
+	for start := 0; start < len(names); start += liteBatch {
+		end := start + liteBatch
+		if end > len(names) {
+			end = len(names)
+		}
+		fns := []func() error{}
+		for _, nodeName := range names[start:end] {
+			nodeName := nodeName // capture loop variable
+			fns = append(fns, func() error {
+				return runLiteNode(logger, name, nodeName, cloudCoreHost, opts)
+			})
+		}
+		if err := errors.UntilErrorConcurrent(fns); err != nil {
+			return nil, err
+		}
+		logger.V(0).Infof("Started %d/%d lite edge nodes", end, len(names))
+	}
+	return names, nil
+}
+
+// ListLite returns the names of the lite edge node containers of the
+// cluster, or of a group of edge nodes created with CreateEdgeNodes
+func ListLite(name string) ([]string, error) {
+	names, err := exec.OutputLines(exec.Command("docker", "ps", "-a",
+		"--filter", fmt.Sprintf("label=%s=%s", clusterLabelKey, name),
+		"--filter", fmt.Sprintf("label=%s=%s", roleLabelKey, liteRole),
+		"--format", "{{.Names}}"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list lite edge nodes of %q", name)
+	}
+	return names, nil
+}
+
+// checkEdgemark checks that the node image has edgemark, `keink build
+// edge-image` leaves it out when the KubeEdge source can't build it
+func checkEdgemark(opts StandaloneOptions) error {
+	args := []string{"run", "--rm", "--entrypoint", "test"}
+	if opts.Arch != "" {
+		args = append(args, "--platform", "linux/"+opts.Arch)
+	}
+	args = append(args, opts.Image, "-x", edgemark)
+	if err := exec.Command("docker", args...).Run(); err != nil {
+		return fmt.Errorf("the node image %s has no edgemark, rebuild it with `keink build edge-image` from a KubeEdge source that builds edgemark to use lite edge nodes", opts.Image)
+	}
+	return nil
+}
+
+// runLiteNode runs edgemark as the entrypoint of a node image container,
+// without systemd nor containerd
+func runLiteNode(logger log.Logger, name, nodeName, cloudCoreHost string, opts StandaloneOptions) error {
+	args := []string{"run",
+		"--detach",
+		"--name", nodeName,
+		"--hostname", nodeName,
+		"--label", fmt.Sprintf("%s=%s", clusterLabelKey, name),
+		"--label", fmt.Sprintf("%s=%s", roleLabelKey, liteRole),
+		"--net", opts.Network,
+		"--restart", "on-failure:3",
+		"--entrypoint", edgemark,
+	}
+	if opts.Arch != "" {
+		args = append(args, "--platform", "linux/"+opts.Arch)
+	}
+	if opts.Resources != nil {
+		limits, err := opts.Resources.DockerArgs()
+		if err != nil {
+			return err
+		}
+		args = append(args, limits...)
+	}
+	args = append(args, opts.Image,
+		"--name="+nodeName,
+		"--token="+opts.Token,
+		fmt.Sprintf("--http-server=https://%s:%d", cloudCoreHost, opts.HTTPSPort),
+		"--websocket-server="+opts.CloudCore,
+		fmt.Sprintf("--node-labels=%s=true", LiteNodeLabelKey),
+	)
+	lines, err := exec.CombinedOutputLines(exec.Command("docker", args...))
+	logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		return errors.Wrapf(err, "failed to run lite edge node %s", nodeName)
+	}
+	return nil
+}
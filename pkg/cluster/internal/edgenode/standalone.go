@@ -0,0 +1,299 @@
+package edgenode
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	shareddocker "sigs.k8s.io/kind/pkg/cluster/shared/providers/docker"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"github.com/kubeedge/keink/pkg/apis/config/edge"
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+	"github.com/kubeedge/keink/pkg/cluster/internal/edgecore"
+	"github.com/kubeedge/keink/pkg/cluster/internal/resources"
+)
+
+// the labels kind puts on its node containers, standalone edge nodes carry
+// them too, so `keink delete cluster` and the keink commands working on
+// edge nodes handle them like the edge nodes of a kind cluster
+const (
+	clusterLabelKey = "io.x-k8s.kind.cluster"
+	roleLabelKey    = "io.x-k8s.kind.role"
+)
+
+// qemuArchs are the qemu-user names of the architectures edge nodes can be
+// emulated as, through the binfmt_misc handlers registered on the host
+var qemuArchs = map[string]string{
+	"amd64":   "x86_64",
+	"arm64":   "aarch64",
+	"arm":     "arm",
+	"riscv64": "riscv64",
+}
+
+// StandaloneOptions are the options of edge nodes joining a cloudcore that
+// keink did not create
+type StandaloneOptions struct {
+	// CloudCore is the ip:port of the cloudhub websocket server
+	CloudCore string
+	// HTTPSPort is the port of the cloudhub https server on the host of
+	// CloudCore, which the certificate is requested from. When 0, it is the
+	// https NodePort if CloudCore is the websocket NodePort of a cloudcore
+	// deployed by keadm, and 10002 otherwise
+	HTTPSPort int
+	// Token is the token of the cloudcore
+	Token string
+	// Count is the number of edge nodes to create
+	Count int
+	// Image is the node image
+	Image string
+	// Network is the docker network of the edge nodes, it is created when
+	// it doesn't exist
+	Network string
+	// Resources limits every edge node container
+	Resources *edge.Resources
+	// Arch is the architecture of the edge nodes, another one than the
+	// host one runs under qemu-user emulation
+	Arch string
+	// Runtime is the container runtime of the edge nodes, containerd by
+	// default, see constants.Runtimes
+	Runtime string
+	// MQTTMode is the MQTT broker of the edgecore eventBus, see
+	// constants.MQTTModes, and MQTTBroker the tcp://ip:port of the external
+	// broker of the modes using one
+	MQTTMode   string
+	MQTTBroker string
+	// Lite runs edgemark, a hollow edgecore with a fake CRI, instead of
+	// systemd, containerd and edgecore, for hundreds of nodes per host
+	Lite bool
+}
+
+// CreateStandalone creates edge node containers named after the group name,
+// without a kind cluster, and joins them to the cloudcore of the options.
+// It returns the names of the new edge nodes
+func CreateStandalone(logger log.Logger, name string, opts StandaloneOptions) ([]string, error) {
+	host, port, err := net.SplitHostPort(opts.CloudCore)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid cloudcore address %q, must be ip:port", opts.CloudCore)
+	}
+	if opts.HTTPSPort == 0 {
+		opts.HTTPSPort = edgecore.HTTPSPort
+		if port == strconv.Itoa(int(constants.CloudCoreNodePorts[10000])) {
+			opts.HTTPSPort = int(constants.CloudCoreNodePorts[edgecore.HTTPSPort])
+		}
+	}
+	if opts.Token == "" {
+		return nil, errors.New("the cloudcore token is required")
+	}
+	if opts.Count < 1 {
+		return nil, errors.New("the count of edge nodes must be at least 1")
+	}
+
+	if opts.Resources != nil {
+		if err := opts.Resources.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkEmulation(opts.Arch); err != nil {
+		return nil, err
+	}
+
+	if err := ensureNetwork(opts.Network); err != nil {
+		return nil, err
+	}
+
+	if opts.Lite {
+		return createLite(logger, name, host, opts)
+	}
+
+	existing, err := shareddocker.ListEdgeNodesByLabel(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list edge nodes of %q: %v", name, err)
+	}
+	taken := map[string]bool{}
+	for _, node := range existing {
+		taken[node.String()] = true
+	}
+
+	// kind names the nodes of a role <name>-<role>, then <name>-<role>2 and
+	// so on, keep going from the edge nodes already there
+	names := []string{}
+	for i := 1; len(names) < opts.Count; i++ {
+		nodeName := fmt.Sprintf("%s-edge-node", name)
+		if i > 1 {
+			nodeName = fmt.Sprintf("%s%d", nodeName, i)
+		}
+		if !taken[nodeName] {
+			names = append(names, nodeName)
+		}
+	}
+
+	for _, nodeName := range names {
+		if err := runNode(logger, name, nodeName, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	all, err := shareddocker.ListEdgeNodesByLabel(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list edge nodes of %q: %v", name, err)
+	}
+	created := map[string]bool{}
+	for _, nodeName := range names {
+		created[nodeName] = true
+	}
+	fns := []func() error{}
+	for _, node := range all {
+		node := node // capture loop variable
+		if !created[node.String()] {
+			continue
+		}
+		fns = append(fns, func() error {
+			return joinStandalone(logger, node, opts)
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// checkEmulation checks that the host can run containers of arch, with a
+// binfmt_misc handler such as the ones `docker run --privileged --rm
+// tonistiigi/binfmt --install all` registers
+func checkEmulation(arch string) error {
+	if arch == "" || arch == runtime.GOARCH {
+		return nil
+	}
+	qemuArch, ok := qemuArchs[arch]
+	if !ok {
+		return fmt.Errorf("unsupported edge node arch %q", arch)
+	}
+	if _, err := os.Stat("/proc/sys/fs/binfmt_misc/qemu-" + qemuArch); err != nil {
+		return fmt.Errorf("no qemu-%s binfmt_misc handler on the host to run %s edge nodes, register one with `docker run --privileged --rm tonistiigi/binfmt --install %s`", qemuArch, arch, arch)
+	}
+	return nil
+}
+
+// ensureNetwork creates the docker network when it doesn't exist
+func ensureNetwork(network string) error {
+	if err := exec.Command("docker", "network", "inspect", network).Run(); err == nil {
+		return nil
+	}
+	if err := exec.Command("docker", "network", "create", network).Run(); err != nil {
+		return errors.Wrapf(err, "failed to create docker network %s", network)
+	}
+	return nil
+}
+
+// runNode runs a node container the way kind runs its worker nodes
+func runNode(logger log.Logger, name, nodeName string, opts StandaloneOptions) error {
+	args := []string{"run",
+		"--detach",
+		"--tty",
+		"--name", nodeName,
+		"--hostname", nodeName,
+		"--label", fmt.Sprintf("%s=%s", clusterLabelKey, name),
+		"--label", fmt.Sprintf("%s=worker", roleLabelKey),
+		"--label", fmt.Sprintf("%s=%s", shareddocker.EdgeNodeLabelKey, shareddocker.EdgeNodeLabelValue),
+		"--net", opts.Network,
+		"--restart=on-failure:1",
+		"--init=false",
+		"--cgroupns=private",
+		"--privileged",
+		"--security-opt", "seccomp=unconfined",
+		"--security-opt", "apparmor=unconfined",
+		"--tmpfs", "/tmp",
+		"--tmpfs", "/run",
+		"--volume", "/var",
+		"--volume", "/lib/modules:/lib/modules:ro",
+	}
+	if opts.Arch != "" {
+		args = append(args, "--platform", "linux/"+opts.Arch)
+	}
+	if opts.Resources != nil {
+		limits, err := opts.Resources.DockerArgs()
+		if err != nil {
+			return err
+		}
+		args = append(args, limits...)
+	}
+	args = append(args, opts.Image)
+	lines, err := exec.CombinedOutputLines(exec.Command("docker", args...))
+	logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		return errors.Wrapf(err, "failed to run edge node %s", nodeName)
+	}
+	return nil
+}
+
+// joinStandalone configures and starts edgecore on the node, with the same
+// steps as the edgecore phase of `keink create kubeedge` for the edge nodes
+// of a kind cluster, and waits for it to connect to cloudcore
+func joinStandalone(logger log.Logger, node nodes.Node, opts StandaloneOptions) error {
+	if err := waitForSystemd(node, time.Minute); err != nil {
+		return err
+	}
+
+	// the node image enables kubelet, edgecore takes its place
+	cmd := node.Command("bash", "-c", "systemctl disable --now kubelet.service || true")
+	lines, err := exec.CombinedOutputLines(cmd)
+	logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		return errors.Wrapf(err, "failed to stop kubelet on %s", node)
+	}
+
+	rt, err := edgecore.StartRuntime(logger, node, opts.Runtime)
+	if err != nil {
+		return err
+	}
+	driver, err := edgecore.CgroupDriver(logger, node, rt)
+	if err != nil {
+		return err
+	}
+	if err := edgecore.Configure(logger, node, edgecore.Config{
+		CloudCore:    opts.CloudCore,
+		HTTPSPort:    opts.HTTPSPort,
+		Token:        opts.Token,
+		Runtime:      rt,
+		CgroupDriver: driver,
+		MQTTMode:     edgecore.MQTTMode(opts.MQTTMode),
+		MQTTBroker:   opts.MQTTBroker,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to configure edgecore on %s", node)
+	}
+	if err := edgecore.CheckCgroupDriver(node, rt); err != nil {
+		return err
+	}
+
+	if opts.Resources != nil {
+		if err := resources.ReserveInEdgeCore(node, opts.Resources); err != nil {
+			return err
+		}
+	}
+
+	if err := edgecore.Start(logger, node); err != nil {
+		return err
+	}
+	return edgecore.WaitConnected(node, opts.CloudCore, 2*time.Minute)
+}
+
+// waitForSystemd waits for systemd in the node container to be up
+func waitForSystemd(node nodes.Node, timeout time.Duration) error {
+	for start := time.Now(); time.Since(start) < timeout; time.Sleep(time.Second) {
+		// is-system-running exits non zero unless running, degraded is fine
+		// too, kubelet fails until it is disabled
+		lines, _ := exec.OutputLines(node.Command("systemctl", "is-system-running"))
+		if len(lines) == 1 && (lines[0] == "running" || lines[0] == "degraded") {
+			return nil
+		}
+	}
+	return fmt.Errorf("systemd did not start in %s within %s", node, timeout)
+}
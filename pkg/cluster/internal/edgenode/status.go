@@ -0,0 +1,126 @@
+package edgenode
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"github.com/kubeedge/keink/pkg/cluster/internal/edgecore"
+	"github.com/kubeedge/keink/pkg/cluster/internal/network"
+)
+
+// Status is the state of edgecore on an edge node
+type Status struct {
+	// EdgeCore is the systemd ActiveState of edgecore
+	EdgeCore string
+	// Version is the version of edgecore
+	Version string
+	// CloudCore is the cloudcore address in the edgecore config
+	CloudCore string
+	// Connected is set when edgecore holds a connection to cloudhub
+	Connected bool
+}
+
+// GetStatus returns the state of edgecore on the node, a node container
+// that is not running, such as a paused one, has an empty state
+func GetStatus(node nodes.Node) (*Status, error) {
+	state, err := exec.Output(exec.Command("docker", "inspect", "-f", "{{.State.Status}}", node.String()))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to inspect %s", node)
+	}
+	if strings.TrimSpace(string(state)) != "running" {
+		return &Status{}, nil
+	}
+
+	status := &Status{}
+	lines, err := exec.OutputLines(node.Command("systemctl", "show", "--property=ActiveState", "--value", "edgecore"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the state of edgecore on %s", node)
+	}
+	status.EdgeCore = firstLine(lines)
+
+	lines, err = exec.OutputLines(node.Command("edgecore", "--version"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the edgecore version on %s", node)
+	}
+	status.Version = strings.TrimPrefix(firstLine(lines), "KubeEdge ")
+
+	// edge nodes that never joined have no config yet
+	if address, err := network.CloudCoreAddress(node); err == nil {
+		status.CloudCore = address
+	}
+
+	// edgecore connects to the port it was configured with, such as a
+	// NodePort, there is no connection to count before it joined
+	server, err := edgecore.CloudHubServer(node)
+	if err != nil {
+		return status, nil
+	}
+	if status.Connected, err = connected(node.Command("bash", "-c", connectionsScript(server))); err != nil {
+		return nil, errors.Wrapf(err, "failed to count the cloudhub connections of %s", node)
+	}
+	return status, nil
+}
+
+// GetLiteStatus returns the state of the lite edge node container nodeName,
+// it has no edgecore service nor config, only the cloudhub connection of
+// edgemark is checked
+func GetLiteStatus(nodeName string) (*Status, error) {
+	state, err := exec.Output(exec.Command("docker", "inspect", "-f", "{{.State.Status}}", nodeName))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to inspect %s", nodeName)
+	}
+	if strings.TrimSpace(string(state)) != "running" {
+		return &Status{}, nil
+	}
+
+	// edgemark is given the cloudhub server as an argument
+	args, err := exec.OutputLines(exec.Command("docker", "inspect", "-f", "{{range .Args}}{{println .}}{{end}}", nodeName))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to inspect %s", nodeName)
+	}
+	server := ""
+	for _, arg := range args {
+		if value, ok := strings.CutPrefix(arg, "--websocket-server="); ok {
+			server = value
+		}
+	}
+	if server == "" {
+		return nil, fmt.Errorf("no cloudhub server in the arguments of %s", nodeName)
+	}
+
+	status := &Status{EdgeCore: LiteState}
+	if host, _, err := net.SplitHostPort(server); err == nil {
+		status.CloudCore = host
+	}
+	if status.Connected, err = connected(exec.Command("docker", "exec", nodeName, "bash", "-c", connectionsScript(server))); err != nil {
+		return nil, errors.Wrapf(err, "failed to count the cloudhub connections of %s", nodeName)
+	}
+	return status, nil
+}
+
+// connectionsScript counts the established connections to the port of the
+// cloudhub server, an ip:port
+func connectionsScript(server string) string {
+	_, port, _ := net.SplitHostPort(server)
+	return fmt.Sprintf("ss -Htn state established '( dport = :%s )' | wc -l", port)
+}
+
+func connected(cmd exec.Cmd) (bool, error) {
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return false, err
+	}
+	return firstLine(lines) != "" && firstLine(lines) != "0", nil
+}
+
+func firstLine(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(lines[0])
+}
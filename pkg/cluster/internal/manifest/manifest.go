@@ -0,0 +1,45 @@
+// Package manifest renders and applies the Kubernetes manifests keink
+// deploys to a cluster
+package manifest
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// Render executes a manifest template with data
+func Render(manifest string, data interface{}) (string, error) {
+	t, err := template.New("manifest").Parse(manifest)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse manifest")
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", errors.Wrap(err, "failed to render manifest")
+	}
+	return buf.String(), nil
+}
+
+// Apply applies a manifest with kubectl on the node, usually the
+// control-plane node which has an admin kubeconfig
+func Apply(logger log.Logger, node nodes.Node, manifest string) error {
+	cmd := node.Command("kubectl", "apply", "-f", "-").SetStdin(strings.NewReader(manifest))
+	lines, err := exec.CombinedOutputLines(cmd)
+	logger.V(3).Info(strings.Join(lines, "\n"))
+	return err
+}
+
+// RenderAndApply renders a manifest template and applies it on the node
+func RenderAndApply(logger log.Logger, node nodes.Node, manifest string, data interface{}) error {
+	rendered, err := Render(manifest, data)
+	if err != nil {
+		return err
+	}
+	return Apply(logger, node, rendered)
+}
@@ -0,0 +1,151 @@
+// Package network injects network faults between edge nodes and cloudcore
+package network
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// cloudCorePorts are the cloudcore ports edgecore connects to: the cloudhub
+// websocket, quic and https servers, the stream and the tunnel ports
+const cloudCorePorts = "10000:10004"
+
+// partitionRules drop the traffic from the edge node to cloudcore. They match
+// the destination before NAT, as in container mode the nat table has already
+// redirected the cloudcore ports to the NodePorts when the filter table runs
+var partitionRules = []string{
+	"OUTPUT -p tcp -m conntrack --ctorigdst %s --ctorigdstport " + cloudCorePorts + " -m comment --comment keink-partition -j DROP",
+	"OUTPUT -p udp -m conntrack --ctorigdst %s --ctorigdstport " + cloudCorePorts + " -m comment --comment keink-partition -j DROP",
+}
+
+// degradeMark is the fwmark of the traffic from the edge node to cloudcore,
+// which tc filters to the netem band
+const degradeMark = "0x100"
+
+// degradeRules mark the traffic from the edge node to cloudcore in the
+// mangle table. Like the partition rules they match the destination before
+// NAT, tc only sees the NodePort destination in container mode
+var degradeRules = []string{
+	"OUTPUT -p tcp -m conntrack --ctorigdst %s --ctorigdstport " + cloudCorePorts + " -m comment --comment keink-degrade -j MARK --set-mark " + degradeMark,
+	"OUTPUT -p udp -m conntrack --ctorigdst %s --ctorigdstport " + cloudCorePorts + " -m comment --comment keink-degrade -j MARK --set-mark " + degradeMark,
+}
+
+// the interface of the kind node container on the kind network
+const nodeInterface = "eth0"
+
+// Degradation describes how the link from an edge node to cloudcore is degraded
+type Degradation struct {
+	// Latency is added to every packet
+	Latency time.Duration
+	// Loss is the percentage of packets dropped
+	Loss float64
+	// Bandwidth limits the rate, in tc units such as 1mbit or 500kbit
+	Bandwidth string
+}
+
+// CloudCoreAddress returns the cloudcore address the edge node connects
+// to, as written in its edgecore config
+func CloudCoreAddress(node nodes.Node) (string, error) {
+	cmd := node.Command("bash", "-c", `sed -n 's|.*httpServer: https://\(.*\):[0-9]*.*|\1|p' /etc/kubeedge/config/edgecore.yaml`)
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read the edgecore config of %s", node)
+	}
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) == "" {
+		return "", fmt.Errorf("no cloudcore address in the edgecore config of %s", node)
+	}
+	return strings.TrimSpace(lines[0]), nil
+}
+
+// Partition drops all traffic from the edge node to the cloudcore ports
+func Partition(node nodes.Node, cloudCoreIP string) error {
+	for _, rule := range partitionRules {
+		rule = fmt.Sprintf(rule, cloudCoreIP)
+		cmd := node.Command("bash", "-c", fmt.Sprintf("iptables -C %[1]s 2>/dev/null || iptables -I %[1]s", rule))
+		if err := cmd.Run(); err != nil {
+			return errors.Wrapf(err, "failed to partition %s", node)
+		}
+	}
+	return nil
+}
+
+// Heal removes the partition and the degradation from the edge node
+func Heal(node nodes.Node, cloudCoreIP string) error {
+	for _, rule := range partitionRules {
+		rule = fmt.Sprintf(rule, cloudCoreIP)
+		cmd := node.Command("bash", "-c", fmt.Sprintf("while iptables -D %s 2>/dev/null; do :; done", rule))
+		if err := cmd.Run(); err != nil {
+			return errors.Wrapf(err, "failed to remove the partition of %s", node)
+		}
+	}
+	if err := unmark(node, cloudCoreIP); err != nil {
+		return errors.Wrapf(err, "failed to remove the degradation of %s", node)
+	}
+
+	cmd := node.Command("bash", "-c", fmt.Sprintf("tc qdisc del dev %s root 2>/dev/null || true", nodeInterface))
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "failed to remove the degradation of %s", node)
+	}
+	return nil
+}
+
+// Degrade applies netem to the traffic from the edge node to cloudcore,
+// other traffic of the node is left untouched
+func Degrade(node nodes.Node, cloudCoreIP string, d Degradation) error {
+	netem := []string{}
+	if d.Latency > 0 {
+		netem = append(netem, fmt.Sprintf("delay %dms", d.Latency.Milliseconds()))
+	}
+	if d.Loss > 0 {
+		netem = append(netem, fmt.Sprintf("loss %g%%", d.Loss))
+	}
+	if d.Bandwidth != "" {
+		netem = append(netem, "rate "+d.Bandwidth)
+	}
+	if len(netem) == 0 {
+		return errors.New("no latency, loss or bandwidth given")
+	}
+
+	// reapplying replaces the previous degradation
+	if err := unmark(node, cloudCoreIP); err != nil {
+		return errors.Wrapf(err, "failed to degrade the network of %s", node)
+	}
+	for _, rule := range degradeRules {
+		rule = fmt.Sprintf(rule, cloudCoreIP)
+		cmd := node.Command("bash", "-c", fmt.Sprintf("iptables -t mangle -A %s", rule))
+		if err := cmd.Run(); err != nil {
+			return errors.Wrapf(err, "failed to degrade the network of %s", node)
+		}
+	}
+
+	// all traffic goes to the first band by default, only the traffic
+	// marked as going to cloudcore is filtered to the netem band
+	script := strings.Join([]string{
+		fmt.Sprintf("tc qdisc del dev %s root 2>/dev/null || true", nodeInterface),
+		fmt.Sprintf("tc qdisc add dev %s root handle 1: prio bands 4 priomap 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0", nodeInterface),
+		fmt.Sprintf("tc qdisc add dev %s parent 1:4 handle 40: netem %s", nodeInterface, strings.Join(netem, " ")),
+		fmt.Sprintf("tc filter add dev %s parent 1: protocol ip prio 1 handle %s fw flowid 1:4", nodeInterface, degradeMark),
+	}, " && ")
+	cmd := node.Command("bash", "-c", script)
+	if _, err := exec.CombinedOutputLines(cmd); err != nil {
+		return errors.Wrapf(err, "failed to degrade the network of %s", node)
+	}
+	return nil
+}
+
+// unmark removes the degrade rules marking the traffic to cloudcore
+func unmark(node nodes.Node, cloudCoreIP string) error {
+	for _, rule := range degradeRules {
+		rule = fmt.Sprintf(rule, cloudCoreIP)
+		cmd := node.Command("bash", "-c", fmt.Sprintf("while iptables -t mangle -D %s 2>/dev/null; do :; done", rule))
+		if err := cmd.Run(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
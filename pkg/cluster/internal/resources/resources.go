@@ -0,0 +1,193 @@
+// Package resources limits edge node containers like small edge devices,
+// and makes edgecore report the limits as the Allocatable of the Node
+package resources
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubeedge/keink/pkg/apis/config/edge"
+)
+
+// Limit applies the limits to the running node container
+func Limit(node nodes.Node, r *edge.Resources) error {
+	args, err := r.DockerArgs()
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	args = append(append([]string{"update"}, args...), node.String())
+	if err := exec.Command("docker", args...).Run(); err != nil {
+		return errors.Wrapf(err, "failed to limit the resources of %s", node)
+	}
+	return nil
+}
+
+// edgeCoreConfig is the edgecore config of the edge nodes
+const edgeCoreConfig = "/etc/kubeedge/config/edgecore.yaml"
+
+// ReserveInEdgeCore sets the host capacity above the limits as the
+// systemReserved of edgecore.yaml, so that the Allocatable of the Node, which
+// the scheduler uses, matches the limits. The Capacity of the Node stays the
+// one of the host: edged reads it from /proc and /sys, which a container
+// can't hide, and has no setting to override it
+func ReserveInEdgeCore(node nodes.Node, r *edge.Resources) error {
+	if r.CPUs == "" && r.Memory == "" {
+		return nil
+	}
+	reserved, err := systemReserved(node, r)
+	if err != nil {
+		return err
+	}
+
+	raw, err := exec.Output(node.Command("cat", edgeCoreConfig))
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the edgecore config of %s", node)
+	}
+	updated, err := setSystemReserved(string(raw), reserved)
+	if err != nil {
+		return errors.Wrapf(err, "invalid edgecore config on %s", node)
+	}
+	if err := nodeutils.WriteFile(node, edgeCoreConfig, updated); err != nil {
+		return errors.Wrapf(err, "failed to reserve resources in the edgecore config of %s", node)
+	}
+	return nil
+}
+
+// edgeCoreReservedConfig is the part of the edgecore config holding the
+// systemReserved
+type edgeCoreReservedConfig struct {
+	Modules struct {
+		Edged struct {
+			TailoredKubeletConfig struct {
+				SystemReserved map[string]string `json:"systemReserved"`
+			} `json:"tailoredKubeletConfig"`
+		} `json:"edged"`
+	} `json:"modules"`
+}
+
+// setSystemReserved replaces the systemReserved of the tailoredKubeletConfig
+// in the edgecore config, or adds it, leaving the other lines as they are
+func setSystemReserved(config string, reserved map[string]string) (string, error) {
+	lines := strings.Split(config, "\n")
+	parent := -1
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "tailoredKubeletConfig:" {
+			parent = i
+			break
+		}
+	}
+	if parent < 0 {
+		return "", errors.New("no tailoredKubeletConfig section")
+	}
+
+	// the section ends at the first line indented no deeper than its key
+	parentIndent := indent(lines[parent])
+	childIndent := parentIndent + 2
+	end := len(lines)
+	for i := parent + 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			continue
+		}
+		if indent(lines[i]) <= parentIndent {
+			end = i
+			break
+		}
+		if i == parent+1 {
+			childIndent = indent(lines[i])
+		}
+	}
+
+	// drop the current systemReserved and its nested lines
+	at := parent + 1
+	for i := parent + 1; i < end; i++ {
+		if indent(lines[i]) == childIndent && strings.HasPrefix(strings.TrimSpace(lines[i]), "systemReserved:") {
+			j := i + 1
+			for j < end && (strings.TrimSpace(lines[j]) == "" || indent(lines[j]) > childIndent) {
+				j++
+			}
+			lines = append(lines[:i], lines[j:]...)
+			at = i
+			break
+		}
+	}
+
+	pad := strings.Repeat(" ", childIndent)
+	block := []string{pad + "systemReserved:"}
+	for _, key := range []string{"cpu", "memory"} {
+		block = append(block, fmt.Sprintf("%s  %s: %q", pad, key, reserved[key]))
+	}
+	lines = append(lines[:at], append(block, lines[at:]...)...)
+	updated := strings.Join(lines, "\n")
+
+	// check the edit landed where edged reads it
+	c := &edgeCoreReservedConfig{}
+	if err := yaml.Unmarshal([]byte(updated), c); err != nil {
+		return "", err
+	}
+	for key, value := range reserved {
+		if c.Modules.Edged.TailoredKubeletConfig.SystemReserved[key] != value {
+			return "", errors.New("failed to set modules.edged.tailoredKubeletConfig.systemReserved")
+		}
+	}
+	return updated, nil
+}
+
+func indent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// systemReserved returns the cpu and memory of the host above the limits
+func systemReserved(node nodes.Node, r *edge.Resources) (map[string]string, error) {
+	reserved := map[string]string{"cpu": "0", "memory": "0"}
+
+	milliCPUs, err := r.MilliCPUs()
+	if err != nil {
+		return nil, err
+	}
+	if milliCPUs > 0 {
+		hostCPUs, err := readInt(node, "getconf _NPROCESSORS_ONLN")
+		if err != nil {
+			return nil, err
+		}
+		if extra := hostCPUs*1000 - milliCPUs; extra > 0 {
+			reserved["cpu"] = fmt.Sprintf("%dm", extra)
+		}
+	}
+
+	memory, err := r.MemoryBytes()
+	if err != nil {
+		return nil, err
+	}
+	if memory > 0 {
+		hostKiB, err := readInt(node, `awk '/^MemTotal:/ {print $2}' /proc/meminfo`)
+		if err != nil {
+			return nil, err
+		}
+		if extra := hostKiB - memory/1024; extra > 0 {
+			reserved["memory"] = fmt.Sprintf("%dKi", extra)
+		}
+	}
+	return reserved, nil
+}
+
+func readInt(node nodes.Node, script string) (int64, error) {
+	lines, err := exec.OutputLines(node.Command("bash", "-c", script))
+	if err != nil || len(lines) != 1 {
+		return 0, fmt.Errorf("failed to read the host capacity from %s: %v", node, err)
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(lines[0]), 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to read the host capacity from %s", node)
+	}
+	return n, nil
+}
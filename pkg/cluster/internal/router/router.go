@@ -0,0 +1,189 @@
+// Package router checks the Rule API of KubeEdge, a message posted to a rest
+// RuleEndpoint of cloudcore is published to the eventbus of an edge node
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubeedge/keink/pkg/cluster/internal/manifest"
+)
+
+// the names of the Rule and RuleEndpoints of the test, and the topic the
+// message is published to on the edge node
+const (
+	ruleName = "keink-router-test"
+	topic    = "keink/router/test"
+	path     = "/keink-router-test"
+)
+
+// routerPort is the port of the cloudcore router
+const routerPort = 9443
+
+// subscriberImage has the mosquitto_sub client
+const subscriberImage = "eclipse-mosquitto:2.0"
+
+const ruleManifest = `apiVersion: rules.kubeedge.io/v1
+kind: RuleEndpoint
+metadata:
+  name: {{ .Name }}-rest
+  namespace: default
+spec:
+  ruleEndpointType: rest
+  properties: {}
+---
+apiVersion: rules.kubeedge.io/v1
+kind: RuleEndpoint
+metadata:
+  name: {{ .Name }}-eventbus
+  namespace: default
+spec:
+  ruleEndpointType: eventbus
+  properties: {}
+---
+apiVersion: rules.kubeedge.io/v1
+kind: Rule
+metadata:
+  name: {{ .Name }}
+  namespace: default
+spec:
+  source: {{ .Name }}-rest
+  sourceResource:
+    path: {{ .Path }}
+  target: {{ .Name }}-eventbus
+  targetResource:
+    topic: {{ .Topic }}
+`
+
+// Test creates a rest to eventbus Rule, posts a message to the router of
+// every cloudcore and waits for it on the MQTT broker of the edge node
+func Test(logger log.Logger, controlPlanes []nodes.Node, edgeNode nodes.Node, timeout time.Duration) error {
+	if len(controlPlanes) == 0 {
+		return errors.New("no control-plane node")
+	}
+	controlPlane := controlPlanes[0]
+
+	if err := manifest.RenderAndApply(logger, controlPlane, ruleManifest, map[string]string{
+		"Name":  ruleName,
+		"Path":  path,
+		"Topic": topic,
+	}); err != nil {
+		return errors.Wrap(err, "failed to create the test Rule, was the cluster created with --enable-router?")
+	}
+	defer func() {
+		_ = controlPlane.Command("kubectl", "delete", "-n", "default", "--ignore-not-found",
+			"rules.rules.kubeedge.io/"+ruleName,
+			"ruleendpoints.rules.kubeedge.io/"+ruleName+"-rest",
+			"ruleendpoints.rules.kubeedge.io/"+ruleName+"-eventbus").Run()
+	}()
+
+	broker, err := edgeBroker(edgeNode)
+	if err != nil {
+		return err
+	}
+
+	// subscribe from the network namespace of the edge node, where the
+	// broker of the edgecore eventBus is reachable
+	seconds := int(timeout.Seconds())
+	subscribe := exec.Command("docker", "run", "--rm",
+		"--network", "container:"+edgeNode.String(),
+		subscriberImage,
+		"mosquitto_sub", "-h", broker.Hostname(), "-p", broker.Port(), "-t", topic, "-C", "1", "-W", fmt.Sprint(seconds))
+	var out bytes.Buffer
+	subscribe.SetStdout(&out)
+	done := make(chan error, 1)
+	go func() {
+		done <- subscribe.Run()
+	}()
+
+	message := fmt.Sprintf("keink-%d", time.Now().UnixNano())
+	for start := time.Now(); time.Since(start) < timeout; time.Sleep(2 * time.Second) {
+		// the Rule may take a moment to reach the router, and only the
+		// cloudcore holding the connection of the edge node delivers
+		postToRouters(controlPlanes, edgeNode.String(), message)
+		select {
+		case err := <-done:
+			if err != nil {
+				return errors.Wrap(err, "failed to receive the message on the edge node")
+			}
+			if got := strings.TrimSpace(out.String()); !strings.Contains(got, message) {
+				return fmt.Errorf("the edge node received %q instead of %q", got, message)
+			}
+			logger.V(0).Infof("Message %s made the round trip from the router to the eventbus of %s", message, edgeNode)
+			return nil
+		default:
+		}
+	}
+	return fmt.Errorf("the message did not reach the eventbus of %s within %s", edgeNode, timeout)
+}
+
+// postToRouters sends the message to the router of every cloudcore, the
+// cloudcore pods in container mode, or the host network cloudcore of every
+// control-plane node otherwise
+func postToRouters(controlPlanes []nodes.Node, edgeNodeName, message string) {
+	controlPlane := controlPlanes[0]
+	podIPs, err := exec.OutputLines(controlPlane.Command("kubectl", "get", "pods", "-n", "kubeedge", "-l", "kubeedge=cloudcore",
+		"--field-selector=status.phase=Running", `-o=jsonpath={range .items[*]}{.status.podIP}{"\n"}{end}`))
+	if err == nil && len(podIPs) > 0 {
+		for _, podIP := range podIPs {
+			if podIP = strings.TrimSpace(podIP); podIP != "" {
+				_ = post(controlPlane, podIP, edgeNodeName, message)
+			}
+		}
+		return
+	}
+	for _, node := range controlPlanes {
+		_ = post(node, "127.0.0.1", edgeNodeName, message)
+	}
+}
+
+// post sends the message from the node to the rest endpoint of the Rule on
+// the router at address
+func post(node nodes.Node, address, edgeNodeName, message string) error {
+	endpoint := fmt.Sprintf("http://%s:%d/%s/default%s", address, routerPort, edgeNodeName, path)
+	return node.Command("curl", "-sf", "-X", "POST", endpoint, "-d", message).Run()
+}
+
+// edgeCoreEventBusConfig is the part of the edgecore config holding the
+// MQTT brokers of the eventBus
+type edgeCoreEventBusConfig struct {
+	Modules struct {
+		EventBus struct {
+			MqttMode           int    `json:"mqttMode"`
+			MqttServerExternal string `json:"mqttServerExternal"`
+			MqttServerInternal string `json:"mqttServerInternal"`
+		} `json:"eventBus"`
+	} `json:"modules"`
+}
+
+// edgeBroker returns the MQTT broker the eventBus of the edge node publishes
+// to, the built-in one with mqttMode 0 and the external one otherwise
+func edgeBroker(node nodes.Node) (*url.URL, error) {
+	raw, err := exec.Output(node.Command("cat", "/etc/kubeedge/config/edgecore.yaml"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the edgecore config of %s", node)
+	}
+	c := &edgeCoreEventBusConfig{}
+	if err := yaml.Unmarshal(raw, c); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the edgecore config of %s", node)
+	}
+	eventBus := c.Modules.EventBus
+	server := eventBus.MqttServerExternal
+	if eventBus.MqttMode == 0 {
+		server = eventBus.MqttServerInternal
+	}
+	broker, err := url.Parse(server)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid MQTT server %q", server)
+	}
+	return broker, nil
+}
@@ -0,0 +1,66 @@
+package cluster
+
+import (
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+
+	"github.com/kubeedge/keink/pkg/cluster/internal/network"
+)
+
+// NetworkDegradation describes how the link from an edge node to cloudcore
+// is degraded, zero values are left out
+type NetworkDegradation struct {
+	// Latency is added to every packet sent to cloudcore
+	Latency time.Duration
+	// Loss is the percentage of packets to cloudcore that are dropped
+	Loss float64
+	// Bandwidth limits the rate to cloudcore, in tc units such as 1mbit
+	Bandwidth string
+}
+
+// PartitionEdgeNode cuts the edge node off from cloudcore, until HealEdgeNode
+// is called. Only the traffic to the cloudcore ports is dropped
+func (p *Provider) PartitionEdgeNode(name, nodeName string) error {
+	node, cloudCoreIP, err := p.edgeNodeLink(name, nodeName)
+	if err != nil {
+		return err
+	}
+	return network.Partition(node, cloudCoreIP)
+}
+
+// HealEdgeNode removes any partition or degradation of the edge node
+func (p *Provider) HealEdgeNode(name, nodeName string) error {
+	node, cloudCoreIP, err := p.edgeNodeLink(name, nodeName)
+	if err != nil {
+		return err
+	}
+	return network.Heal(node, cloudCoreIP)
+}
+
+// DegradeEdgeNode adds latency, packet loss or a bandwidth limit to the
+// traffic from the edge node to cloudcore, replacing any earlier degradation
+func (p *Provider) DegradeEdgeNode(name, nodeName string, d NetworkDegradation) error {
+	node, cloudCoreIP, err := p.edgeNodeLink(name, nodeName)
+	if err != nil {
+		return err
+	}
+	return network.Degrade(node, cloudCoreIP, network.Degradation{
+		Latency:   d.Latency,
+		Loss:      d.Loss,
+		Bandwidth: d.Bandwidth,
+	})
+}
+
+// edgeNodeLink returns the edge node and the cloudcore IP it connects to
+func (p *Provider) edgeNodeLink(name, nodeName string) (nodes.Node, string, error) {
+	node, err := p.edgeNode(name, nodeName)
+	if err != nil {
+		return nil, "", err
+	}
+	cloudCoreIP, err := network.CloudCoreAddress(node)
+	if err != nil {
+		return nil, "", err
+	}
+	return node, cloudCoreIP, nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
 	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 	sharedcreate "sigs.k8s.io/kind/pkg/cluster/shared/create"
 	shareddocker "sigs.k8s.io/kind/pkg/cluster/shared/providers/docker"
 	"sigs.k8s.io/kind/pkg/shared/apis/config"
@@ -40,17 +41,65 @@ func (p *Provider) CreateKubeEdge(name string, options ...CreateOption) error {
 	}
 
 	PreProcessClusterOptions(opts)
+	if err := internalcreate.Validate(opts); err != nil {
+		return err
+	}
 
-	// create k8s cluster using kind library directly.
-	err := sharedcreate.Cluster(p.Logger, p.Provider, &opts.ClusterOptions)
-	if err != nil {
-		return fmt.Errorf("failed to create k8s cluster: %v", err)
+	if opts.Resume {
+		// the k8s cluster already exists, only the KubeEdge phases are left
+		if err := p.resumeClusterOptions(opts); err != nil {
+			return err
+		}
+	} else {
+		if opts.ExposeCloudCore {
+			if err := exposeCloudCore(opts); err != nil {
+				return err
+			}
+		}
+
+		// create k8s cluster using kind library directly.
+		err := sharedcreate.Cluster(p.Logger, p.Provider, &opts.ClusterOptions)
+		if err != nil {
+			return fmt.Errorf("failed to create k8s cluster: %v", err)
+		}
 	}
 
 	// create kubeedge cluster
 	return internalcreate.Cluster(p.Logger, p.Provider, opts)
 }
 
+// resumeClusterOptions prepares the options of a cluster that was retained
+// after a failed creation, the nodes must still exist
+func (p *Provider) resumeClusterOptions(opts *internalcreate.ClusterOptions) error {
+	// kind applies the name override while creating, do the same here
+	if opts.NameOverride != "" {
+		opts.Config.Name = opts.NameOverride
+	}
+
+	n, err := p.Provider.ListNodes(opts.Config.Name)
+	if err != nil {
+		return fmt.Errorf("failed to list nodes of cluster %q: %v", opts.Config.Name, err)
+	}
+	if len(n) == 0 {
+		return fmt.Errorf("cannot resume cluster %q: no nodes found, was it created with --retain?", opts.Config.Name)
+	}
+
+	// the address cloudcore was exposed at is not part of the config
+	if opts.ExposedAddress == "" {
+		node, err := nodeutils.BootstrapControlPlaneNode(n)
+		if err != nil {
+			return err
+		}
+		if opts.ExposedAddress, err = exposedAddress(node); err != nil {
+			return err
+		}
+	}
+
+	// a resumed cluster is kept when it fails again, so it can be resumed once more
+	opts.Retain = true
+	return nil
+}
+
 // PreProcessClusterOptions do some pre-processing on ClusterOptions so that kind api can recognize it
 // will overwrite the input argument directly
 func PreProcessClusterOptions(opts *internalcreate.ClusterOptions) {
@@ -87,7 +136,6 @@ func PreProcessClusterOptions(opts *internalcreate.ClusterOptions) {
 				opts.ClusterOptions.Config.Nodes[index].Labels = make(map[string]string)
 			}
 			opts.ClusterOptions.Config.Nodes[index].Labels[shareddocker.EdgeNodeLabelKey] = shareddocker.EdgeNodeLabelValue
-			break
 		}
 	}
 
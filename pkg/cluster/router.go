@@ -0,0 +1,44 @@
+package cluster
+
+import (
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	shareddocker "sigs.k8s.io/kind/pkg/cluster/shared/providers/docker"
+
+	"github.com/kubeedge/keink/pkg/cluster/internal/router"
+)
+
+// TestRouter creates a rest to eventbus Rule and checks that a message
+// posted to the cloudcore router reaches the eventbus of the edge node
+// nodeName, or of the first edge node when it is empty. The cluster must
+// have been created with the router enabled
+func (p *Provider) TestRouter(name, nodeName string, timeout time.Duration) error {
+	n, err := p.Provider.ListNodes(name)
+	if err != nil {
+		return fmt.Errorf("failed to list nodes of cluster %q: %v", name, err)
+	}
+	controlPlanes, err := nodeutils.ControlPlaneNodes(n)
+	if err != nil {
+		return err
+	}
+
+	var node nodes.Node
+	if nodeName != "" {
+		if node, err = p.edgeNode(name, nodeName); err != nil {
+			return err
+		}
+	} else {
+		edgeNodes, err := shareddocker.ListEdgeNodesByLabel(name)
+		if err != nil {
+			return fmt.Errorf("failed to list edge nodes of cluster %q: %v", name, err)
+		}
+		if len(edgeNodes) == 0 {
+			return fmt.Errorf("no edge nodes found for cluster %q", name)
+		}
+		node = edgeNodes[0]
+	}
+	return router.Test(p.Logger, controlPlanes, node, timeout)
+}
@@ -0,0 +1,80 @@
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/kubeedge/keink/pkg/apis/config/defaults"
+	"github.com/kubeedge/keink/pkg/apis/config/edge"
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+	"github.com/kubeedge/keink/pkg/cluster/internal/edgenode"
+)
+
+// EdgeNodesOptions are the options of edge nodes joining a cloudcore that
+// keink did not create, such as one in a remote or a k3s cluster
+type EdgeNodesOptions struct {
+	// CloudCore is the ip:port of the cloudhub websocket server
+	CloudCore string
+	// HTTPSPort is the port of the cloudhub https server on the host of
+	// CloudCore, derived from the port of CloudCore when 0
+	HTTPSPort int
+	// Token is the token of the cloudcore
+	Token string
+	// Count is the number of edge nodes to create
+	Count int
+	// Image is the node image, defaults.Image by default
+	Image string
+	// Network is the docker network of the edge nodes, kind by default
+	Network string
+	// Resources limits every edge node container, nil for no limits
+	Resources *edge.Resources
+	// Arch is the architecture of the edge nodes, such as arm64, another
+	// one than the host one runs under qemu-user emulation
+	Arch string
+	// Runtime is the container runtime of the edge nodes, containerd by
+	// default, see constants.Runtimes
+	Runtime string
+	// MQTTMode is the MQTT broker of the edgecore eventBus, see
+	// constants.MQTTModes, MQTTBroker is the tcp://ip:port of the external
+	// broker, which the modes other than the internal one need
+	MQTTMode   string
+	MQTTBroker string
+	// Lite creates lite edge nodes named <name>-lite-edge-node and so on,
+	// running only the edgemark hollow edgecore, to scale test cloudcore
+	Lite bool
+}
+
+// CreateEdgeNodes creates edge node containers named <name>-edge-node,
+// <name>-edge-node2 and so on, without a kind cluster, and joins them to
+// the cloudcore of the options. It returns the names of the new nodes.
+// `keink delete cluster --name <name>` removes them
+func (p *Provider) CreateEdgeNodes(name string, opts EdgeNodesOptions) ([]string, error) {
+	if opts.Image == "" {
+		opts.Image = defaults.Image
+	}
+	if opts.Network == "" {
+		opts.Network = "kind"
+	}
+	if opts.Runtime != "" && !contains(constants.Runtimes, opts.Runtime) {
+		return nil, fmt.Errorf("unknown runtime %q, must be one of %v", opts.Runtime, constants.Runtimes)
+	}
+	if opts.MQTTMode != "" && !contains(constants.MQTTModes, opts.MQTTMode) {
+		return nil, fmt.Errorf("unknown MQTT mode %q, must be one of %v", opts.MQTTMode, constants.MQTTModes)
+	}
+	if opts.MQTTMode != "" && opts.MQTTMode != constants.MQTTModeInternal && opts.MQTTBroker == "" {
+		return nil, fmt.Errorf("MQTT mode %q needs the address of the external broker", opts.MQTTMode)
+	}
+	return edgenode.CreateStandalone(p.Logger, name, edgenode.StandaloneOptions{
+		CloudCore:  opts.CloudCore,
+		HTTPSPort:  opts.HTTPSPort,
+		Token:      opts.Token,
+		Count:      opts.Count,
+		Image:      opts.Image,
+		Network:    opts.Network,
+		Resources:  opts.Resources,
+		Arch:       opts.Arch,
+		Runtime:    opts.Runtime,
+		MQTTMode:   opts.MQTTMode,
+		MQTTBroker: opts.MQTTBroker,
+		Lite:       opts.Lite,
+	})
+}
@@ -0,0 +1,178 @@
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	shareddocker "sigs.k8s.io/kind/pkg/cluster/shared/providers/docker"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"github.com/kubeedge/keink/pkg/cluster/internal/cloudcore"
+	"github.com/kubeedge/keink/pkg/cluster/internal/create/actions/kubeedge"
+	"github.com/kubeedge/keink/pkg/cluster/internal/edgenode"
+)
+
+// EdgeNode is an edge node of a cluster
+type EdgeNode struct {
+	// Name is the name of the node container and of the Node
+	Name string `json:"name"`
+	// Ready is the status of the Ready condition of the Node, empty when
+	// there is no Node or no control-plane to get it from
+	Ready string `json:"ready"`
+	// EdgeCore is the systemd state of edgecore, edgenode.LiteState on
+	// lite edge nodes, and empty when the node container is not running
+	EdgeCore string `json:"edgecore"`
+	// Version is the version of edgecore
+	Version string `json:"version"`
+	// CloudCore is the cloudcore address edgecore connects to
+	CloudCore string `json:"cloudcore"`
+	// Connected is set when edgecore holds a connection to cloudcore
+	Connected bool `json:"connected"`
+	// NodeGroup is the nodeGroup of the node in the config
+	NodeGroup string `json:"nodeGroup,omitempty"`
+}
+
+// CloudCore is a cloudcore service, or pod in container mode
+type CloudCore struct {
+	// Node is the control-plane node it runs on
+	Node string `json:"node"`
+	// State is the systemd state of the service, or the phase of the pod
+	State string `json:"state"`
+	// Connections is the number of edge nodes connected to it
+	Connections int `json:"connections"`
+}
+
+// KubeEdgeStatus is the state of KubeEdge in a cluster
+type KubeEdgeStatus struct {
+	// Cluster is the name of the cluster
+	Cluster string `json:"cluster"`
+	// Mode is how cloudcore runs, one of constants.CloudCoreModeSystemd
+	// and constants.CloudCoreModeContainer
+	Mode string `json:"mode"`
+	// Version is the version of cloudcore
+	Version string `json:"version"`
+	// AdvertiseAddresses are the addresses edge nodes reach cloudcore at
+	AdvertiseAddresses []string `json:"advertiseAddresses"`
+	// CloudCores are the cloudcore instances
+	CloudCores []CloudCore `json:"cloudcores"`
+	// EdgeNodes are the edge nodes of the cluster
+	EdgeNodes []EdgeNode `json:"edgeNodes"`
+}
+
+// ListEdgeNodes returns the edge nodes of the cluster, or of a group of
+// edge nodes created with CreateEdgeNodes, and their state, lite edge
+// nodes included
+func (p *Provider) ListEdgeNodes(name string) ([]EdgeNode, error) {
+	edgeNodes, err := shareddocker.ListEdgeNodesByLabel(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list edge nodes of cluster %q: %v", name, err)
+	}
+	liteNodes, err := edgenode.ListLite(name)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]EdgeNode, len(edgeNodes)+len(liteNodes))
+	fns := []func() error{}
+	for i, node := range edgeNodes {
+		i, node := i, node // capture loop variables
+		fns = append(fns, func() error {
+			status, err := edgenode.GetStatus(node)
+			if err != nil {
+				return err
+			}
+			result[i] = EdgeNode{
+				Name:      node.String(),
+				EdgeCore:  status.EdgeCore,
+				Version:   status.Version,
+				CloudCore: status.CloudCore,
+				Connected: status.Connected,
+			}
+			return nil
+		})
+	}
+	for i, nodeName := range liteNodes {
+		i, nodeName := len(edgeNodes)+i, nodeName // capture loop variables
+		fns = append(fns, func() error {
+			status, err := edgenode.GetLiteStatus(nodeName)
+			if err != nil {
+				return err
+			}
+			result[i] = EdgeNode{
+				Name:      nodeName,
+				EdgeCore:  status.EdgeCore,
+				CloudCore: status.CloudCore,
+				Connected: status.Connected,
+			}
+			return nil
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return nil, err
+	}
+
+	// the edge nodes created with CreateEdgeNodes have no control-plane
+	controlPlane, err := p.controlPlane(name)
+	if err != nil {
+		return result, nil
+	}
+	lines, err := exec.OutputLines(controlPlane.Command("kubectl", "get", "nodes",
+		fmt.Sprintf(`-o=jsonpath={range .items[*]}{.metadata.name}{"\t"}{.status.conditions[?(@.type=="Ready")].status}{"\t"}{.metadata.labels.%s}{"\n"}{end}`,
+			strings.ReplaceAll(kubeedge.NodeGroupLabelKey, ".", `\.`))))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the nodes")
+	}
+	for _, line := range lines {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			continue
+		}
+		for i := range result {
+			if result[i].Name == fields[0] {
+				result[i].Ready = fields[1]
+				result[i].NodeGroup = fields[2]
+			}
+		}
+	}
+	return result, nil
+}
+
+// KubeEdgeStatus returns how cloudcore runs, its version, advertise
+// addresses and connections, and the state of the edge nodes
+func (p *Provider) KubeEdgeStatus(name string) (*KubeEdgeStatus, error) {
+	n, err := p.Provider.ListNodes(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes of cluster %q: %v", name, err)
+	}
+	controlPlanes, err := nodeutils.ControlPlaneNodes(n)
+	if err != nil {
+		return nil, err
+	}
+	if len(controlPlanes) == 0 {
+		return nil, fmt.Errorf("no control-plane node found for cluster %q", name)
+	}
+
+	status, err := cloudcore.GetStatus(controlPlanes)
+	if err != nil {
+		return nil, err
+	}
+	result := &KubeEdgeStatus{
+		Cluster:            name,
+		Mode:               status.Mode,
+		Version:            status.Version,
+		AdvertiseAddresses: status.AdvertiseAddresses,
+	}
+	for _, instance := range status.Instances {
+		result.CloudCores = append(result.CloudCores, CloudCore{
+			Node:        instance.Node,
+			State:       instance.State,
+			Connections: instance.Connections,
+		})
+	}
+	if result.EdgeNodes, err = p.ListEdgeNodes(name); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -9,6 +9,7 @@ import (
 	"sigs.k8s.io/kind/pkg/log"
 
 	"github.com/kubeedge/keink/pkg/build/edgeimage"
+	"github.com/kubeedge/keink/pkg/cluster/constants"
 )
 
 type flagpole struct {
@@ -18,6 +19,9 @@ type flagpole struct {
 	BaseImage string
 	KubeRoot  string
 	Arch      string
+	Runtimes  []string
+
+	CloudCoreImage string
 }
 
 // NewCommand returns a new cobra.Command for building
@@ -87,6 +91,16 @@ func newBuildCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		"",
 		"architecture to build for, defaults to the host architecture",
 	)
+	cmd.Flags().StringVar(
+		&flags.CloudCoreImage, "cloudcore-image",
+		edgeimage.DefaultCloudCoreImage,
+		"name:tag of the cloudcore image built along and preloaded into the node image, empty to skip it",
+	)
+	cmd.Flags().StringSliceVar(
+		&flags.Runtimes, "runtime",
+		nil,
+		fmt.Sprintf("container runtimes to install besides containerd, any of %v", constants.Runtimes),
+	)
 	return cmd
 }
 
@@ -101,6 +115,8 @@ func runE(logger log.Logger, flags *flagpole, args []string) error {
 		edgeimage.WithKubeEdgeRoot(kubeRoot),
 		edgeimage.WithLogger(logger),
 		edgeimage.WithArch(flags.Arch),
+		edgeimage.WithRuntimes(flags.Runtimes...),
+		edgeimage.WithCloudCoreImage(flags.CloudCoreImage),
 	); err != nil {
 		return fmt.Errorf("failed to build edge-image: %v", err)
 	}
@@ -0,0 +1,80 @@
+// Package certs implements the `certs` command
+package certs
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	kindcluster "sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+	"sigs.k8s.io/kind/pkg/shared/cli"
+
+	"github.com/kubeedge/keink/pkg/cmd/internal/provider"
+)
+
+type flagpole struct {
+	Name     string
+	Node     string
+	Validity time.Duration
+}
+
+// NewCommand returns a new cobra.Command for certs
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "certs",
+		Short: "Issues edge node certificates, one of [rotate, expire]",
+		Long:  "Issues edge node certificates signed by the cloudcore CA, to exercise the certificate rotation of edgecore",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := cmd.Help()
+			if err != nil {
+				return err
+			}
+			return errors.New("Subcommand is required")
+		},
+	}
+	cmd.AddCommand(newRotateCommand(logger))
+	cmd.AddCommand(newExpireCommand(logger))
+	return cmd
+}
+
+func newRotateCommand(logger log.Logger) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "rotate",
+		Short: "Replaces the certificate of an edge node with a short-lived one",
+		Long:  "Replaces the certificate of an edge node with one valid for --validity, restarts edgecore and waits for the node to be Ready. edgecore renews it from cloudcore before it expires",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return provider.New(logger).RotateEdgeCert(flags.Name, flags.Node, flags.Validity)
+		},
+	}
+	addFlags(cmd, flags)
+	cmd.Flags().DurationVar(&flags.Validity, "validity", 10*time.Minute, "how long the certificate is valid")
+	return cmd
+}
+
+func newExpireCommand(logger log.Logger) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "expire",
+		Short: "Replaces the certificate of an edge node with an expired one",
+		Long:  "Replaces the certificate of an edge node with an expired one and restarts edgecore",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return provider.New(logger).ExpireEdgeCert(flags.Name, flags.Node)
+		},
+	}
+	addFlags(cmd, flags)
+	return cmd
+}
+
+func addFlags(cmd *cobra.Command, flags *flagpole) {
+	cmd.Flags().StringVarP(&flags.Name, "name", "n", kindcluster.DefaultName, "the cluster context name")
+	cmd.Flags().StringVar(&flags.Node, "node", "", "the edge node")
+	_ = cmd.MarkFlagRequired("node")
+}
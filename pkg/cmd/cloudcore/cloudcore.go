@@ -0,0 +1,58 @@
+// Package cloudcore implements the `cloudcore` command, which operates the
+// cloudcore instances of a cluster
+package cloudcore
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	kindcluster "sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+	"sigs.k8s.io/kind/pkg/shared/cli"
+
+	"github.com/kubeedge/keink/pkg/cmd/internal/provider"
+)
+
+type flagpole struct {
+	Name    string
+	Timeout time.Duration
+}
+
+// NewCommand returns a new cobra.Command for operating cloudcore
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "cloudcore",
+		Short: "Operates the cloudcore instances of a cluster",
+		Long:  "Operates the cloudcore instances of a cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := cmd.Help()
+			if err != nil {
+				return err
+			}
+			return errors.New("Subcommand is required")
+		},
+	}
+	cmd.AddCommand(newFailoverCommand(logger))
+	return cmd
+}
+
+func newFailoverCommand(logger log.Logger) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "failover",
+		Short: "Tests that edge nodes survive the loss of the active cloudcore",
+		Long:  "Kills the cloudcore most edge nodes are connected to, checks that every edge node reconnects to another cloudcore, then starts it again",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			p := provider.New(logger)
+			return p.TestCloudCoreFailover(flags.Name, flags.Timeout)
+		},
+	}
+	cmd.Flags().StringVarP(&flags.Name, "name", "n", kindcluster.DefaultName, "the cluster context name")
+	cmd.Flags().DurationVar(&flags.Timeout, "timeout", 5*time.Minute, "how long to wait for the edge nodes to reconnect")
+	return cmd
+}
@@ -7,15 +7,15 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
-	kindcluster "sigs.k8s.io/kind/pkg/cluster"
 	"sigs.k8s.io/kind/pkg/cmd"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/log"
 	"sigs.k8s.io/kind/pkg/shared/cli"
-	"sigs.k8s.io/kind/pkg/shared/runtime"
 
 	"github.com/kubeedge/keink/pkg/apis/config/defaults"
 	"github.com/kubeedge/keink/pkg/cluster"
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+	"github.com/kubeedge/keink/pkg/cmd/internal/provider"
 )
 
 type flagpole struct {
@@ -27,6 +27,16 @@ type flagpole struct {
 	Kubeconfig       string
 	AdvertiseAddress string
 	ContainerMode    bool
+	Resume           bool
+	SkipPhases       []string
+	Addons           []string
+	EdgeCNI          string
+	EnableStream     bool
+	ExposeCloudCore  bool
+	CACert           string
+	CAKey            string
+	MQTTMode         string
+	EnableRouter     bool
 }
 
 // NewCommand returns a new cobra.Command for cluster creation
@@ -34,8 +44,8 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
 		Args:  cobra.NoArgs,
 		Use:   "create",
-		Short: "Creates one of [cluster]",
-		Long:  "Creates one of local Kubernetes cluster (cluster)",
+		Short: "Creates one of [kubeedge, device, edge-nodes]",
+		Long:  "Creates one of local KubeEdge cluster (kubeedge), simulated device (device) or edge nodes joining an existing cloudcore (edge-nodes)",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := cmd.Help()
 			if err != nil {
@@ -45,6 +55,8 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		},
 	}
 	cmd.AddCommand(newKubeEdgeCommand(logger, streams))
+	cmd.AddCommand(newDeviceCommand(logger))
+	cmd.AddCommand(newEdgeNodesCommand(logger, streams))
 	return cmd
 }
 
@@ -71,15 +83,22 @@ func newKubeEdgeCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command
 	cmd.Flags().StringVar(&flags.Kubeconfig, "kubeconfig", "", "sets kubeconfig path instead of $KUBECONFIG or $HOME/.kube/config")
 	cmd.Flags().StringVar(&flags.AdvertiseAddress, "advertise-address", "", "sets cloudcore advertise-address")
 	cmd.Flags().BoolVar(&flags.ContainerMode, "container-mode", false, "sets cloudcore in container mode")
+	cmd.Flags().BoolVar(&flags.Resume, "resume", false, "continue the KubeEdge bootstrap of a cluster retained with --retain from its first incomplete phase")
+	cmd.Flags().BoolVar(&flags.ExposeCloudCore, "expose-cloudcore", false, "map the cloudcore ports 10000, 10002, 10003 and 10004 of the control-plane to the host, so machines outside the cluster can join it")
+	cmd.Flags().StringVar(&flags.CACert, "ca-cert", "", "PEM encoded CA certificate for cloudcore to issue its server and the edge certificates with, requires --ca-key")
+	cmd.Flags().StringVar(&flags.CAKey, "ca-key", "", "PEM encoded ECDSA key of the --ca-cert CA, in SEC1 encoding")
+	cmd.Flags().StringVar(&flags.MQTTMode, "mqtt-mode", constants.MQTTModeInternal, fmt.Sprintf("MQTT broker of the edgecore eventBus, one of %v", constants.MQTTModes))
+	cmd.Flags().BoolVar(&flags.EnableRouter, "enable-router", false, "enable the cloudcore router and the edgecore serviceBus, so Rules can carry messages between the cloud and edge nodes")
+	cmd.Flags().BoolVar(&flags.EnableStream, "enable-stream", false, "enable the cloudStream/edgeStream tunnel, so kubectl logs/exec work against pods on edge nodes")
+	cmd.Flags().StringVar(&flags.EdgeCNI, "edge-cni", constants.EdgeCNINone, fmt.Sprintf("pod network setup of the edge nodes, one of %v", constants.EdgeCNIs))
+	cmd.Flags().StringSliceVar(&flags.Addons, "addon", nil, fmt.Sprintf("add-ons to install after KubeEdge is up, any of %v", constants.Addons))
+	cmd.Flags().StringSliceVar(&flags.SkipPhases, "skip-phases", nil, fmt.Sprintf("KubeEdge bootstrap phases to skip, any of %v", constants.KubeEdgePhases))
 
 	return cmd
 }
 
 func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
-	kubeedgeProvider := cluster.NewProvider(
-		kindcluster.ProviderWithLogger(logger),
-		runtime.GetDefault(logger),
-	)
+	kubeedgeProvider := provider.New(logger)
 
 	// handle config flag, we might need to read from stdin
 	withConfig, err := configOption(flags.Config, streams.In)
@@ -93,10 +112,20 @@ func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
 		cluster.CreateWithKubeconfigPath(flags.Kubeconfig),
 		cluster.CreateWithNodeImage(flags.ImageName),
 		cluster.CreateWithWaitForReady(flags.Wait),
+		cluster.CreateWithRetain(flags.Retain),
 
 		// the below options are KubeEdge customized configurations
 		cluster.CreateWithAdvertiseAddress(flags.AdvertiseAddress),
 		cluster.CreateWithContainerMode(flags.ContainerMode),
+		cluster.CreateWithExposeCloudCore(flags.ExposeCloudCore),
+		cluster.CreateWithCA(flags.CACert, flags.CAKey),
+		cluster.CreateWithMQTTMode(flags.MQTTMode),
+		cluster.CreateWithRouter(flags.EnableRouter),
+		cluster.CreateWithResume(flags.Resume),
+		cluster.CreateWithSkipPhases(flags.SkipPhases...),
+		cluster.CreateWithEdgeCNI(flags.EdgeCNI),
+		cluster.CreateWithStream(flags.EnableStream),
+		cluster.CreateWithAddons(flags.Addons...),
 	); err != nil {
 		return fmt.Errorf("failed to create kubeedge cluster: %v", err)
 	}
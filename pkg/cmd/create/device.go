@@ -0,0 +1,49 @@
+package create
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	kindcluster "sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/log"
+	"sigs.k8s.io/kind/pkg/shared/cli"
+
+	"github.com/kubeedge/keink/pkg/cluster"
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+	"github.com/kubeedge/keink/pkg/cmd/internal/provider"
+)
+
+type deviceFlagpole struct {
+	Name     string
+	Node     string
+	Device   string
+	Protocol string
+	Model    string
+}
+
+// newDeviceCommand returns a new cobra.Command for simulated device creation
+func newDeviceCommand(logger log.Logger) *cobra.Command {
+	flags := &deviceFlagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "device",
+		Short: "Creates a simulated device attached to an edge node",
+		Long:  "Runs a device simulator on the kind network and creates a DeviceModel and a Device bound to the edge node",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			p := provider.New(logger)
+			return p.CreateDevice(flags.Name, flags.Node, cluster.DeviceOptions{
+				Name:      flags.Device,
+				Protocol:  flags.Protocol,
+				ModelFile: flags.Model,
+			})
+		},
+	}
+	cmd.Flags().StringVarP(&flags.Name, "name", "n", kindcluster.DefaultName, "the cluster context name")
+	cmd.Flags().StringVar(&flags.Node, "node", "", "the edge node the device is bound to")
+	cmd.Flags().StringVar(&flags.Device, "device", "", "name of the Device (default <node>-<protocol>)")
+	cmd.Flags().StringVar(&flags.Protocol, "protocol", constants.DeviceProtocolModbus, fmt.Sprintf("protocol of the device, one of %v", constants.DeviceProtocols))
+	cmd.Flags().StringVar(&flags.Model, "model", "", "path to a DeviceModel manifest, a model with a single INT property by default")
+	_ = cmd.MarkFlagRequired("node")
+	return cmd
+}
@@ -0,0 +1,91 @@
+package create
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"github.com/kubeedge/keink/pkg/apis/config/defaults"
+	"github.com/kubeedge/keink/pkg/apis/config/edge"
+	"github.com/kubeedge/keink/pkg/cluster"
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+	"github.com/kubeedge/keink/pkg/cmd/internal/provider"
+)
+
+type edgeNodesFlagpole struct {
+	Name       string
+	CloudCore  string
+	HTTPSPort  int
+	Token      string
+	Count      int
+	ImageName  string
+	Network    string
+	CPUs       string
+	Memory     string
+	Pids       int64
+	Arch       string
+	Runtime    string
+	MQTTMode   string
+	MQTTBroker string
+	Lite       bool
+}
+
+// newEdgeNodesCommand returns a new cobra.Command for creating edge nodes
+// that join an existing cloudcore
+func newEdgeNodesCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &edgeNodesFlagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "edge-nodes",
+		Short: "Creates edge nodes joining an existing cloudcore",
+		Long:  "Creates edge node containers on a docker network, without a kind cluster, and joins them to an existing cloudcore",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p := provider.New(logger)
+			var limits *edge.Resources
+			if flags.CPUs != "" || flags.Memory != "" || flags.Pids > 0 {
+				limits = &edge.Resources{CPUs: flags.CPUs, Memory: flags.Memory, Pids: flags.Pids}
+			}
+			names, err := p.CreateEdgeNodes(flags.Name, cluster.EdgeNodesOptions{
+				CloudCore:  flags.CloudCore,
+				HTTPSPort:  flags.HTTPSPort,
+				Token:      flags.Token,
+				Count:      flags.Count,
+				Image:      flags.ImageName,
+				Network:    flags.Network,
+				Resources:  limits,
+				Arch:       flags.Arch,
+				Runtime:    flags.Runtime,
+				MQTTMode:   flags.MQTTMode,
+				MQTTBroker: flags.MQTTBroker,
+				Lite:       flags.Lite,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create edge nodes: %v", err)
+			}
+			for _, name := range names {
+				fmt.Fprintln(streams.Out, name)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&flags.Name, "name", "n", "edge", "name of the group of edge nodes, the nodes are named <name>-edge-node, <name>-edge-node2 and so on")
+	cmd.Flags().StringVar(&flags.CloudCore, "cloudcore", "", "ip:port of the cloudcore websocket server to join")
+	cmd.Flags().IntVar(&flags.HTTPSPort, "cloudcore-https-port", 0, "port of the cloudcore https server the edge nodes request their certificate from, on the --cloudcore address; 30002 when --cloudcore is on the NodePort 30000 of a cloudcore deployed by keadm, 10002 otherwise")
+	cmd.Flags().StringVar(&flags.Token, "token", "", "token of the cloudcore")
+	cmd.Flags().IntVar(&flags.Count, "count", 1, "number of edge nodes to create")
+	cmd.Flags().StringVar(&flags.ImageName, "image", defaults.Image, "node docker image to use for the edge nodes")
+	cmd.Flags().StringVar(&flags.Network, "network", "kind", "docker network of the edge nodes, created when it doesn't exist")
+	cmd.Flags().StringVar(&flags.CPUs, "cpus", "", "CPU limit of every edge node, such as 0.5, reported in the Allocatable of the Node, its Capacity stays the host one")
+	cmd.Flags().StringVar(&flags.Memory, "memory", "", "memory limit of every edge node, such as 512Mi, reported in the Allocatable of the Node, its Capacity stays the host one")
+	cmd.Flags().Int64Var(&flags.Pids, "pids", 0, "process limit of every edge node")
+	cmd.Flags().StringVar(&flags.Arch, "arch", "", "architecture of the edge nodes, such as arm64, emulated with qemu-user when it isn't the host one")
+	cmd.Flags().StringVar(&flags.Runtime, "runtime", constants.RuntimeContainerd, fmt.Sprintf("container runtime of the edge nodes, one of %v, installed in the image with `keink build edge-image --runtime`", constants.Runtimes))
+	cmd.Flags().StringVar(&flags.MQTTMode, "mqtt-mode", constants.MQTTModeInternal, fmt.Sprintf("MQTT broker of the edgecore eventBus, one of %v", constants.MQTTModes))
+	cmd.Flags().StringVar(&flags.MQTTBroker, "mqtt-broker", "", "tcp://ip:port of the external MQTT broker, required unless --mqtt-mode is internal")
+	cmd.Flags().BoolVar(&flags.Lite, "lite", false, "create lite edge nodes running only the edgemark hollow edgecore, which registers the Node and syncs its pods without running them")
+	_ = cmd.MarkFlagRequired("cloudcore")
+	_ = cmd.MarkFlagRequired("token")
+	return cmd
+}
@@ -0,0 +1,88 @@
+// Package delete implements the `delete` command, kind's delete command with
+// the KubeEdge specific subcommands added
+package delete
+
+import (
+	"github.com/spf13/cobra"
+	kindcluster "sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	kinddelete "sigs.k8s.io/kind/pkg/cmd/kind/delete"
+	"sigs.k8s.io/kind/pkg/log"
+	"sigs.k8s.io/kind/pkg/shared/cli"
+
+	"github.com/kubeedge/keink/pkg/cmd/internal/provider"
+)
+
+type deviceFlagpole struct {
+	Name   string
+	Device string
+}
+
+// NewCommand returns a new cobra.Command for delete
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	// keep the kind subcommands (cluster, clusters)
+	cmd := kinddelete.NewCommand(logger, streams)
+	cmd.Short = "Deletes one of [cluster, device]"
+	cmd.Long = "Deletes one of [cluster, device]"
+
+	// the device simulators and the MQTT broker are not kind nodes, remove
+	// them with the cluster
+	for _, c := range cmd.Commands() {
+		c := c // capture loop variable
+		switch c.Name() {
+		case "cluster":
+			deleteCluster := c.RunE
+			c.RunE = func(cmd *cobra.Command, args []string) error {
+				if err := deleteCluster(cmd, args); err != nil {
+					return err
+				}
+				name, err := c.Flags().GetString("name")
+				if err != nil {
+					return err
+				}
+				return provider.New(logger).DeleteContainers(name)
+			}
+		case "clusters":
+			deleteClusters := c.RunE
+			c.RunE = func(cmd *cobra.Command, args []string) error {
+				if err := deleteClusters(cmd, args); err != nil {
+					return err
+				}
+				all, err := c.Flags().GetBool("all")
+				if err != nil {
+					return err
+				}
+				if all {
+					return provider.New(logger).DeleteContainers("")
+				}
+				for _, name := range args {
+					if err := provider.New(logger).DeleteContainers(name); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+		}
+	}
+
+	cmd.AddCommand(newDeviceCommand(logger))
+	return cmd
+}
+
+// newDeviceCommand returns a new cobra.Command for deleting simulated devices
+func newDeviceCommand(logger log.Logger) *cobra.Command {
+	flags := &deviceFlagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "device",
+		Short: "Deletes simulated devices",
+		Long:  "Deletes a simulated device, or all of them when --device is not set, with their Device objects",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return provider.New(logger).DeleteDevices(flags.Name, flags.Device)
+		},
+	}
+	cmd.Flags().StringVarP(&flags.Name, "name", "n", kindcluster.DefaultName, "the cluster context name")
+	cmd.Flags().StringVar(&flags.Device, "device", "", "the device to delete, all of them by default")
+	return cmd
+}
@@ -0,0 +1,70 @@
+// Package edge implements the `edge` command, which simulates outages of
+// edge nodes
+package edge
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	kindcluster "sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+	"sigs.k8s.io/kind/pkg/shared/cli"
+
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+	"github.com/kubeedge/keink/pkg/cmd/internal/provider"
+)
+
+type flagpole struct {
+	Name string
+	Node string
+	Wait time.Duration
+}
+
+// NewCommand returns a new cobra.Command for edge node outage simulation
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "edge",
+		Short: "Simulates outages of edge nodes",
+		Long:  "Stops, pauses or crashes edge nodes and brings them back, waiting for the Node to become NotReady or Ready",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := cmd.Help()
+			if err != nil {
+				return err
+			}
+			return errors.New("Subcommand is required")
+		},
+	}
+	cmd.AddCommand(newOperationCommand(logger, constants.EdgeNodeStop, "Stops edgecore on an edge node"))
+	cmd.AddCommand(newOperationCommand(logger, constants.EdgeNodeStart, "Starts edgecore on an edge node"))
+	cmd.AddCommand(newOperationCommand(logger, constants.EdgeNodePause, "Pauses an edge node container, like a power loss"))
+	cmd.AddCommand(newOperationCommand(logger, constants.EdgeNodeUnpause, "Unpauses an edge node container"))
+	cmd.AddCommand(newOperationCommand(logger, constants.EdgeNodeCrash, "Kills edgecore with SIGKILL and waits for it to be restarted"))
+	return cmd
+}
+
+func newOperationCommand(logger log.Logger, operation, short string) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   operation,
+		Short: short,
+		Long:  short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			p := provider.New(logger)
+			if err := p.OperateEdgeNode(flags.Name, flags.Node, operation, flags.Wait); err != nil {
+				return err
+			}
+			logger.V(0).Infof("Ran %s on edge node %s", operation, flags.Node)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&flags.Name, "name", "n", kindcluster.DefaultName, "the cluster context name")
+	cmd.Flags().StringVar(&flags.Node, "node", "", "the edge node to act on")
+	cmd.Flags().DurationVar(&flags.Wait, "wait", 5*time.Minute, "wait for the Node to become NotReady or Ready, 0 to return right away")
+	_ = cmd.MarkFlagRequired("node")
+	return cmd
+}
@@ -0,0 +1,47 @@
+package get
+
+import (
+	"github.com/spf13/cobra"
+	kindcluster "sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/log"
+	"sigs.k8s.io/kind/pkg/shared/cli"
+
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+	"github.com/kubeedge/keink/pkg/cmd/internal/output"
+	"github.com/kubeedge/keink/pkg/cmd/internal/provider"
+)
+
+type edgeNodesFlagpole struct {
+	Name   string
+	Output string
+}
+
+// newEdgeNodesCommand returns a new cobra.Command for listing the edge nodes
+// of a cluster
+func newEdgeNodesCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &edgeNodesFlagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "edge-nodes",
+		Short: "Lists the edge nodes of a cluster and their state",
+		Long:  "Lists the edge nodes of a cluster, or of a group created with `keink create edge-nodes`, with the state and version of edgecore and its connection to cloudcore",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			if err := output.Validate(flags.Output); err != nil {
+				return err
+			}
+			edgeNodes, err := provider.New(logger).ListEdgeNodes(flags.Name)
+			if err != nil {
+				return err
+			}
+			if flags.Output == constants.OutputJSON {
+				return output.JSON(streams.Out, edgeNodes)
+			}
+			return output.EdgeNodes(streams.Out, edgeNodes)
+		},
+	}
+	cmd.Flags().StringVarP(&flags.Name, "name", "n", kindcluster.DefaultName, "the cluster context name")
+	cmd.Flags().StringVarP(&flags.Output, "output", "o", constants.OutputTable, "output format, table or json")
+	return cmd
+}
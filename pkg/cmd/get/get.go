@@ -0,0 +1,23 @@
+// Package get implements the `get` command, kind's get command with the
+// KubeEdge specific subcommands added
+package get
+
+import (
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kind/pkg/cmd"
+	kindget "sigs.k8s.io/kind/pkg/cmd/kind/get"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// NewCommand returns a new cobra.Command for get
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	// keep the kind subcommands (clusters, nodes, kubeconfig)
+	cmd := kindget.NewCommand(logger, streams)
+	cmd.Short = "Gets one of [clusters, nodes, kubeconfig, join-command, token, edge-nodes]"
+	cmd.Long = "Gets one of [clusters, nodes, kubeconfig, join-command, token, edge-nodes]"
+
+	cmd.AddCommand(newJoinCommandCommand(logger, streams))
+	cmd.AddCommand(newTokenCommand(logger, streams))
+	cmd.AddCommand(newEdgeNodesCommand(logger, streams))
+	return cmd
+}
@@ -0,0 +1,42 @@
+package get
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	kindcluster "sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/log"
+	"sigs.k8s.io/kind/pkg/shared/cli"
+
+	"github.com/kubeedge/keink/pkg/cmd/internal/provider"
+)
+
+type joinCommandFlagpole struct {
+	Name   string
+	HostIP string
+}
+
+// newJoinCommandCommand returns a new cobra.Command for getting the keadm
+// join command of a cluster
+func newJoinCommandCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &joinCommandFlagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "join-command",
+		Short: "Prints the keadm join command of a cluster created with --expose-cloudcore",
+		Long:  "Prints the keadm join command, with the host IP and the current token, that a machine outside the cluster runs to join it as an edge node",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			joinCommand, err := provider.New(logger).JoinCommand(flags.Name, flags.HostIP)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(streams.Out, joinCommand)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&flags.Name, "name", "n", kindcluster.DefaultName, "the cluster context name")
+	cmd.Flags().StringVar(&flags.HostIP, "host-ip", "", "the host address the edge node connects to, the address the cloudcore ports were exposed at by default")
+	return cmd
+}
@@ -0,0 +1,39 @@
+package get
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	kindcluster "sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/log"
+	"sigs.k8s.io/kind/pkg/shared/cli"
+
+	"github.com/kubeedge/keink/pkg/cmd/internal/provider"
+)
+
+type tokenFlagpole struct {
+	Name string
+}
+
+// newTokenCommand returns a new cobra.Command for getting the cloudcore token
+func newTokenCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &tokenFlagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "token",
+		Short: "Prints the token edge nodes join the cluster with",
+		Long:  "Prints the current cloudcore token, read from the tokensecret of the running cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			token, err := provider.New(logger).CloudCoreToken(flags.Name)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(streams.Out, token)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&flags.Name, "name", "n", kindcluster.DefaultName, "the cluster context name")
+	return cmd
+}
@@ -0,0 +1,77 @@
+// Package output prints the state of keink clusters as tables or JSON
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kubeedge/keink/pkg/cluster"
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+)
+
+// Validate checks the output format
+func Validate(format string) error {
+	for _, f := range constants.Outputs {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown output format %q, must be one of %v", format, constants.Outputs)
+}
+
+// JSON prints v as indented JSON
+func JSON(w io.Writer, v interface{}) error {
+	raw, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(raw))
+	return err
+}
+
+// EdgeNodes prints the edge nodes as a table
+func EdgeNodes(w io.Writer, edgeNodes []cluster.EdgeNode) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tREADY\tEDGECORE\tVERSION\tCLOUDCORE\tCONNECTED\tNODEGROUP")
+	for _, node := range edgeNodes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%t\t%s\n",
+			node.Name, orNone(node.Ready), orNone(node.EdgeCore), orNone(node.Version),
+			orNone(node.CloudCore), node.Connected, orNone(node.NodeGroup))
+	}
+	return tw.Flush()
+}
+
+// Status prints the state of cloudcore, then the edge nodes as a table
+func Status(w io.Writer, status *cluster.KubeEdgeStatus) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 3, ' ', 0)
+	fmt.Fprintf(tw, "Cluster:\t%s\n", status.Cluster)
+	fmt.Fprintf(tw, "CloudCore mode:\t%s\n", status.Mode)
+	fmt.Fprintf(tw, "CloudCore version:\t%s\n", orNone(status.Version))
+	fmt.Fprintf(tw, "Advertise addresses:\t%s\n", orNone(strings.Join(status.AdvertiseAddresses, ",")))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(tw, "CLOUDCORE\tSTATE\tCONNECTIONS")
+	for _, c := range status.CloudCores {
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", c.Node, orNone(c.State), c.Connections)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	return EdgeNodes(w, status.EdgeNodes)
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
@@ -0,0 +1,19 @@
+// Package provider builds the cluster Provider the keink commands run with
+package provider
+
+import (
+	kindcluster "sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/log"
+	"sigs.k8s.io/kind/pkg/shared/runtime"
+
+	"github.com/kubeedge/keink/pkg/cluster"
+)
+
+// New returns a Provider logging to logger, with the node provider picked
+// the way kind does
+func New(logger log.Logger) *cluster.Provider {
+	return cluster.NewProvider(
+		kindcluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+	)
+}
@@ -0,0 +1,137 @@
+// Package network implements the `network` command, which injects network
+// faults between edge nodes and cloudcore
+package network
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	kindcluster "sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+	"sigs.k8s.io/kind/pkg/shared/cli"
+
+	"github.com/kubeedge/keink/pkg/cluster"
+	"github.com/kubeedge/keink/pkg/cmd/internal/provider"
+)
+
+type flagpole struct {
+	Name      string
+	Node      string
+	Duration  time.Duration
+	Latency   time.Duration
+	Loss      float64
+	Bandwidth string
+}
+
+// NewCommand returns a new cobra.Command for network fault injection
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "network",
+		Short: "Injects network faults between edge nodes and cloudcore",
+		Long:  "Partitions, degrades or heals the network between edge nodes and cloudcore",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := cmd.Help()
+			if err != nil {
+				return err
+			}
+			return errors.New("Subcommand is required")
+		},
+	}
+	cmd.AddCommand(newPartitionCommand(logger))
+	cmd.AddCommand(newDegradeCommand(logger))
+	cmd.AddCommand(newHealCommand(logger))
+	return cmd
+}
+
+func newPartitionCommand(logger log.Logger) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "partition",
+		Short: "Cuts an edge node off from cloudcore",
+		Long:  "Drops the traffic from an edge node to the cloudcore ports, until healed or --duration has passed",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			p := provider.New(logger)
+			if err := p.PartitionEdgeNode(flags.Name, flags.Node); err != nil {
+				return err
+			}
+			logger.V(0).Infof("Edge node %s is partitioned from cloudcore", flags.Node)
+			return healAfter(logger, p, flags)
+		},
+	}
+	addCommonFlags(cmd, flags)
+	cmd.Flags().DurationVar(&flags.Duration, "duration", 0, "heal the edge node after this duration, by default the partition is kept until `keink network heal`")
+	return cmd
+}
+
+func newDegradeCommand(logger log.Logger) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "degrade",
+		Short: "Degrades the link from an edge node to cloudcore",
+		Long:  "Adds latency, packet loss or a bandwidth limit to the traffic from an edge node to cloudcore",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			p := provider.New(logger)
+			if err := p.DegradeEdgeNode(flags.Name, flags.Node, cluster.NetworkDegradation{
+				Latency:   flags.Latency,
+				Loss:      flags.Loss,
+				Bandwidth: flags.Bandwidth,
+			}); err != nil {
+				return err
+			}
+			logger.V(0).Infof("Link from edge node %s to cloudcore is degraded", flags.Node)
+			return healAfter(logger, p, flags)
+		},
+	}
+	addCommonFlags(cmd, flags)
+	cmd.Flags().DurationVar(&flags.Duration, "duration", 0, "heal the edge node after this duration, by default the degradation is kept until `keink network heal`")
+	cmd.Flags().DurationVar(&flags.Latency, "latency", 0, "latency added to every packet, e.g. 200ms")
+	cmd.Flags().Float64Var(&flags.Loss, "loss", 0, "percentage of packets dropped, e.g. 10")
+	cmd.Flags().StringVar(&flags.Bandwidth, "bandwidth", "", "bandwidth limit in tc units, e.g. 1mbit")
+	return cmd
+}
+
+func newHealCommand(logger log.Logger) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "heal",
+		Short: "Removes the network faults of an edge node",
+		Long:  "Removes the partition and degradation between an edge node and cloudcore",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			if err := provider.New(logger).HealEdgeNode(flags.Name, flags.Node); err != nil {
+				return err
+			}
+			logger.V(0).Infof("Edge node %s is healed", flags.Node)
+			return nil
+		},
+	}
+	addCommonFlags(cmd, flags)
+	return cmd
+}
+
+func addCommonFlags(cmd *cobra.Command, flags *flagpole) {
+	cmd.Flags().StringVarP(&flags.Name, "name", "n", kindcluster.DefaultName, "the cluster context name")
+	cmd.Flags().StringVar(&flags.Node, "node", "", "the edge node to act on")
+	_ = cmd.MarkFlagRequired("node")
+}
+
+// healAfter heals the edge node once the duration has passed, if one is set
+func healAfter(logger log.Logger, p *cluster.Provider, flags *flagpole) error {
+	if flags.Duration <= 0 {
+		return nil
+	}
+	time.Sleep(flags.Duration)
+	if err := p.HealEdgeNode(flags.Name, flags.Node); err != nil {
+		return err
+	}
+	logger.V(0).Infof("Edge node %s is healed after %s", flags.Node, flags.Duration)
+	return nil
+}
@@ -7,15 +7,22 @@ import (
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kind/pkg/cmd"
 	"sigs.k8s.io/kind/pkg/cmd/kind/completion"
-	"sigs.k8s.io/kind/pkg/cmd/kind/delete"
 	"sigs.k8s.io/kind/pkg/cmd/kind/export"
-	"sigs.k8s.io/kind/pkg/cmd/kind/get"
 	"sigs.k8s.io/kind/pkg/cmd/kind/load"
 	"sigs.k8s.io/kind/pkg/cmd/kind/version"
 	"sigs.k8s.io/kind/pkg/log"
 
 	"github.com/kubeedge/keink/pkg/cmd/build"
+	"github.com/kubeedge/keink/pkg/cmd/certs"
+	"github.com/kubeedge/keink/pkg/cmd/cloudcore"
 	"github.com/kubeedge/keink/pkg/cmd/create"
+	"github.com/kubeedge/keink/pkg/cmd/delete"
+	"github.com/kubeedge/keink/pkg/cmd/edge"
+	"github.com/kubeedge/keink/pkg/cmd/get"
+	"github.com/kubeedge/keink/pkg/cmd/network"
+	"github.com/kubeedge/keink/pkg/cmd/rotate"
+	"github.com/kubeedge/keink/pkg/cmd/router"
+	"github.com/kubeedge/keink/pkg/cmd/status"
 )
 
 type flagpole struct {
@@ -70,10 +77,10 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	// for example: kind get clusters/nodes can be repleaced by keink get clusters/nodes directly
 	// modification：just import kind commands directly
 	cmd.AddCommand(completion.NewCommand(logger, streams))
+	// keink delete command, kind delete subcommands plus device
 	// TODO: maybe we can add a delete kubeedge subcommand, just for stopping edgenodes and stop cloudcore/edgecore components
 	cmd.AddCommand(delete.NewCommand(logger, streams))
 	cmd.AddCommand(export.NewCommand(logger, streams))
-	cmd.AddCommand(get.NewCommand(logger, streams))
 	cmd.AddCommand(version.NewCommand(logger, streams))
 	cmd.AddCommand(load.NewCommand(logger, streams))
 
@@ -92,6 +99,30 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	buildCmd := build.NewCommand(logger, streams)
 	cmd.AddCommand(buildCmd)
 
+	// keink get command, kind get subcommands plus the KubeEdge ones
+	cmd.AddCommand(get.NewCommand(logger, streams))
+
+	// keink network partition/degrade/heal commands
+	cmd.AddCommand(network.NewCommand(logger, streams))
+
+	// keink edge stop/start/pause/unpause/crash commands
+	cmd.AddCommand(edge.NewCommand(logger, streams))
+
+	// keink cloudcore failover command
+	cmd.AddCommand(cloudcore.NewCommand(logger, streams))
+
+	// keink rotate token command
+	cmd.AddCommand(rotate.NewCommand(logger, streams))
+
+	// keink certs rotate/expire commands
+	cmd.AddCommand(certs.NewCommand(logger, streams))
+
+	// keink router test command
+	cmd.AddCommand(router.NewCommand(logger, streams))
+
+	// keink status command
+	cmd.AddCommand(status.NewCommand(logger, streams))
+
 	return cmd
 }
 
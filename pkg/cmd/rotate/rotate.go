@@ -0,0 +1,63 @@
+// Package rotate implements the `rotate` command
+package rotate
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	kindcluster "sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+	"sigs.k8s.io/kind/pkg/shared/cli"
+
+	"github.com/kubeedge/keink/pkg/cmd/internal/provider"
+)
+
+type tokenFlagpole struct {
+	Name            string
+	UpdateEdgeNodes bool
+}
+
+// NewCommand returns a new cobra.Command for rotate
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "rotate",
+		Short: "Rotates one of [token]",
+		Long:  "Rotates one of [token]",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := cmd.Help()
+			if err != nil {
+				return err
+			}
+			return errors.New("Subcommand is required")
+		},
+	}
+	cmd.AddCommand(newTokenCommand(logger, streams))
+	return cmd
+}
+
+// newTokenCommand returns a new cobra.Command for rotating the cloudcore token
+func newTokenCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &tokenFlagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "token",
+		Short: "Makes cloudcore issue a new token",
+		Long:  "Makes cloudcore issue a new token and prints it, optionally updating the edge nodes to join again with it",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			p := provider.New(logger)
+			token, err := p.RotateCloudCoreToken(flags.Name, flags.UpdateEdgeNodes)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(streams.Out, token)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&flags.Name, "name", "n", kindcluster.DefaultName, "the cluster context name")
+	cmd.Flags().BoolVar(&flags.UpdateEdgeNodes, "update-edge-nodes", false, "write the new token to the edge nodes and restart them, so they request their certificate again with it")
+	return cmd
+}
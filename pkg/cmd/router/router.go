@@ -0,0 +1,60 @@
+// Package router implements the `router` command
+package router
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	kindcluster "sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+	"sigs.k8s.io/kind/pkg/shared/cli"
+
+	"github.com/kubeedge/keink/pkg/cmd/internal/provider"
+)
+
+type testFlagpole struct {
+	Name    string
+	Node    string
+	Timeout time.Duration
+}
+
+// NewCommand returns a new cobra.Command for router
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "router",
+		Short: "Checks the cloudcore router, one of [test]",
+		Long:  "Checks the cloudcore router and the Rule API of a cluster created with --enable-router",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := cmd.Help()
+			if err != nil {
+				return err
+			}
+			return errors.New("Subcommand is required")
+		},
+	}
+	cmd.AddCommand(newTestCommand(logger))
+	return cmd
+}
+
+// newTestCommand returns a new cobra.Command for the router round trip
+func newTestCommand(logger log.Logger) *cobra.Command {
+	flags := &testFlagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "test",
+		Short: "Sends a message through a rest to eventbus Rule",
+		Long:  "Creates a rest to eventbus Rule, posts a message to the cloudcore router and waits for it on the MQTT broker of the edge node",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			p := provider.New(logger)
+			return p.TestRouter(flags.Name, flags.Node, flags.Timeout)
+		},
+	}
+	cmd.Flags().StringVarP(&flags.Name, "name", "n", kindcluster.DefaultName, "the cluster context name")
+	cmd.Flags().StringVar(&flags.Node, "node", "", "the edge node receiving the message (default the first edge node)")
+	cmd.Flags().DurationVar(&flags.Timeout, "timeout", 2*time.Minute, "how long to wait for the message")
+	return cmd
+}
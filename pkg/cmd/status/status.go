@@ -0,0 +1,48 @@
+// Package status implements the `status` command, which prints the state
+// of KubeEdge in a cluster
+package status
+
+import (
+	"github.com/spf13/cobra"
+	kindcluster "sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/log"
+	"sigs.k8s.io/kind/pkg/shared/cli"
+
+	"github.com/kubeedge/keink/pkg/cluster/constants"
+	"github.com/kubeedge/keink/pkg/cmd/internal/output"
+	"github.com/kubeedge/keink/pkg/cmd/internal/provider"
+)
+
+type flagpole struct {
+	Name   string
+	Output string
+}
+
+// NewCommand returns a new cobra.Command for the state of KubeEdge
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "status",
+		Short: "Prints the state of KubeEdge in a cluster",
+		Long:  "Prints how cloudcore runs, its version, advertise addresses and connections, and the state of the edge nodes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			if err := output.Validate(flags.Output); err != nil {
+				return err
+			}
+			status, err := provider.New(logger).KubeEdgeStatus(flags.Name)
+			if err != nil {
+				return err
+			}
+			if flags.Output == constants.OutputJSON {
+				return output.JSON(streams.Out, status)
+			}
+			return output.Status(streams.Out, status)
+		},
+	}
+	cmd.Flags().StringVarP(&flags.Name, "name", "n", kindcluster.DefaultName, "the cluster context name")
+	cmd.Flags().StringVarP(&flags.Output, "output", "o", constants.OutputTable, "output format, table or json")
+	return cmd
+}
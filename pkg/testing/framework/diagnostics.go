@@ -0,0 +1,87 @@
+package framework
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	shareddocker "sigs.k8s.io/kind/pkg/cluster/shared/providers/docker"
+)
+
+// kubeEdgeResources are dumped with the diagnostics, the cluster state
+// KubeEdge tests usually fail on
+var kubeEdgeResources = []string{
+	"nodes",
+	"pods",
+	"events",
+	"objectsyncs.reliablesyncs.kubeedge.io",
+	"clusterobjectsyncs.reliablesyncs.kubeedge.io",
+	"devices.devices.kubeedge.io",
+	"nodegroups.apps.kubeedge.io",
+	"edgeapplications.apps.kubeedge.io",
+}
+
+// CollectDiagnostics records the logs kind collects from every node, the
+// edgecore and cloudcore logs and the KubeEdge resources under dir
+func (f *Framework) CollectDiagnostics(dir string) error {
+	if err := f.kind.CollectLogs(f.Name, dir); err != nil {
+		return err
+	}
+
+	kubeEdgeDir := filepath.Join(dir, "kubeedge")
+	if err := os.MkdirAll(kubeEdgeDir, 0o755); err != nil {
+		return err
+	}
+	for _, resource := range kubeEdgeResources {
+		// the resources of the features not enabled are missing, record
+		// the error of kubectl instead
+		lines, _ := f.Provider.Kubectl(f.Name, "get", resource, "--all-namespaces", "-o", "wide")
+		name := strings.SplitN(resource, ".", 2)[0] + ".txt"
+		if err := os.WriteFile(filepath.Join(kubeEdgeDir, name), []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+			return err
+		}
+	}
+	// cloudcore runs as a systemd service, whose logs are in the journal of
+	// the control-plane nodes, or as pods in container mode
+	lines, _ := f.Provider.Kubectl(f.Name, "logs", "-n", "kubeedge", "-l", "kubeedge=cloudcore", "--all-containers", "--prefix", "--tail=-1")
+	if err := os.WriteFile(filepath.Join(kubeEdgeDir, "cloudcore-pods.log"), []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+		return err
+	}
+
+	// f.EdgeNodes is only set once the cluster is up, list the edge nodes
+	// of a failed creation from docker
+	edgeNodes, err := shareddocker.ListEdgeNodesByLabel(f.Name)
+	if err != nil {
+		return err
+	}
+	for _, edgeNode := range edgeNodes {
+		node := edgeNode.String()
+		nodeDir := filepath.Join(dir, node)
+		if err := os.MkdirAll(nodeDir, 0o755); err != nil {
+			return err
+		}
+		lines, _ := f.Provider.ExecOnEdgeNode(f.Name, node, "journalctl", "--no-pager", "-u", "edgecore")
+		if err := os.WriteFile(filepath.Join(nodeDir, "edgecore.log"), []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DiagnoseOnFailure records the diagnostics under a directory of the
+// ArtifactsDir named after the test, when the test fails
+func (f *Framework) DiagnoseOnFailure(t testing.TB) {
+	t.Helper()
+	t.Cleanup(func() {
+		if !t.Failed() {
+			return
+		}
+		dir := filepath.Join(f.ArtifactsDir, strings.ReplaceAll(t.Name(), "/", "_"))
+		if err := f.CollectDiagnostics(dir); err != nil {
+			t.Logf("failed to record diagnostics: %v", err)
+			return
+		}
+		t.Logf("diagnostics recorded in %s", dir)
+	})
+}
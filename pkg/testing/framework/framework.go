@@ -0,0 +1,203 @@
+// Package framework runs e2e test suites against a keink cluster, created
+// once per suite from TestMain and deleted when the suite ends, with the
+// diagnostics of the cluster recorded when it fails:
+//
+//	var f *framework.Framework
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(framework.Main(m, framework.Options{}, &f))
+//	}
+//
+//	func TestEdgeNode(t *testing.T) {
+//		f.DiagnoseOnFailure(t)
+//		node := f.EdgeNodes[0]
+//		f.PartitionEdge(t, node)
+//		f.WaitForEdgeNodeNotReady(t, node, 2*time.Minute)
+//		pods, err := f.Clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+//		...
+//	}
+package framework
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"testing"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	kindcluster "sigs.k8s.io/kind/pkg/cluster"
+	shareddocker "sigs.k8s.io/kind/pkg/cluster/shared/providers/docker"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/log"
+	"sigs.k8s.io/kind/pkg/shared/runtime"
+
+	"github.com/kubeedge/keink/pkg/cluster"
+)
+
+// DefaultName is the name of the cluster of a suite by default
+const DefaultName = "keink-e2e"
+
+// Options are the options of the cluster of a suite
+type Options struct {
+	// Name of the cluster, DefaultName by default
+	Name string
+	// CreateOptions are passed to Provider.CreateKubeEdge, such as
+	// cluster.CreateWithConfigFile or cluster.CreateWithNodeImage. The
+	// cluster is always created with cluster.CreateWithRetain, so that a
+	// failed creation can be diagnosed before the teardown deletes it
+	CreateOptions []cluster.CreateOption
+	// Retain keeps the cluster after the suite, to debug it
+	Retain bool
+	// ArtifactsDir is where the diagnostics are recorded, $ARTIFACTS or a
+	// new temporary directory by default
+	ArtifactsDir string
+	// Logger logs the cluster creation, to stderr by default
+	Logger log.Logger
+}
+
+// Framework is the cluster of a suite
+type Framework struct {
+	// Name of the cluster
+	Name string
+	// Provider creates the cluster and operates its edge nodes
+	Provider *cluster.Provider
+	// Kubeconfig is the kubeconfig of the cluster
+	Kubeconfig string
+	// KubeconfigPath is a file holding Kubeconfig, for tools run by the tests
+	KubeconfigPath string
+	// Clientset is a client of the API server of the cluster
+	Clientset kubernetes.Interface
+	// EdgeNodes are the names of the edge nodes, sorted
+	EdgeNodes []string
+	// ArtifactsDir is where the diagnostics are recorded
+	ArtifactsDir string
+
+	kind   *kindcluster.Provider
+	retain bool
+}
+
+// Main creates the cluster, sets f, runs the tests of m and deletes the
+// cluster, after recording the diagnostics when the tests failed. It returns
+// the exit code for os.Exit
+func Main(m *testing.M, opts Options, f **Framework) int {
+	framework, err := New(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create the e2e cluster: %v\n", err)
+		if framework != nil {
+			_ = framework.Teardown(true)
+		}
+		return 1
+	}
+	*f = framework
+
+	code := m.Run()
+	if err := framework.Teardown(code != 0); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to tear down the e2e cluster: %v\n", err)
+		if code == 0 {
+			code = 1
+		}
+	}
+	return code
+}
+
+// New creates the cluster of the options. When the creation fails, the
+// returned Framework is not nil if there is anything to tear down
+func New(opts Options) (*Framework, error) {
+	if opts.Name == "" {
+		opts.Name = DefaultName
+	}
+	if opts.Logger == nil {
+		opts.Logger = cmd.NewLogger()
+	}
+	if opts.ArtifactsDir == "" {
+		opts.ArtifactsDir = os.Getenv("ARTIFACTS")
+	}
+	if opts.ArtifactsDir == "" {
+		dir, err := os.MkdirTemp("", opts.Name+"-artifacts-")
+		if err != nil {
+			return nil, err
+		}
+		opts.ArtifactsDir = dir
+	}
+
+	f := &Framework{
+		Name: opts.Name,
+		Provider: cluster.NewProvider(
+			kindcluster.ProviderWithLogger(opts.Logger),
+			runtime.GetDefault(opts.Logger),
+		),
+		ArtifactsDir: opts.ArtifactsDir,
+		kind: kindcluster.NewProvider(
+			kindcluster.ProviderWithLogger(opts.Logger),
+			runtime.GetDefault(opts.Logger),
+		),
+		retain: opts.Retain,
+	}
+
+	// a failed creation keeps the nodes, Teardown records their
+	// diagnostics and deletes them
+	createOptions := append(append([]cluster.CreateOption{}, opts.CreateOptions...), cluster.CreateWithRetain(true))
+	if err := f.Provider.CreateKubeEdge(f.Name, createOptions...); err != nil {
+		return f, err
+	}
+
+	var err error
+	if f.Kubeconfig, err = f.kind.KubeConfig(f.Name, false); err != nil {
+		return f, err
+	}
+	file, err := os.CreateTemp("", f.Name+"-kubeconfig-")
+	if err != nil {
+		return f, err
+	}
+	f.KubeconfigPath = file.Name()
+	_, err = file.WriteString(f.Kubeconfig)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return f, err
+	}
+
+	config, err := clientcmd.RESTConfigFromKubeConfig([]byte(f.Kubeconfig))
+	if err != nil {
+		return f, fmt.Errorf("failed to load the kubeconfig of cluster %q: %v", f.Name, err)
+	}
+	if f.Clientset, err = kubernetes.NewForConfig(config); err != nil {
+		return f, fmt.Errorf("failed to create the clientset of cluster %q: %v", f.Name, err)
+	}
+
+	edgeNodes, err := shareddocker.ListEdgeNodesByLabel(f.Name)
+	if err != nil {
+		return f, fmt.Errorf("failed to list edge nodes of cluster %q: %v", f.Name, err)
+	}
+	for _, node := range edgeNodes {
+		f.EdgeNodes = append(f.EdgeNodes, node.String())
+	}
+	sort.Strings(f.EdgeNodes)
+	return f, nil
+}
+
+// Teardown records the diagnostics when failed is set, then deletes the
+// cluster and the containers keink runs next to it, unless it is retained
+func (f *Framework) Teardown(failed bool) error {
+	if failed {
+		if err := f.CollectDiagnostics(f.ArtifactsDir); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to record diagnostics: %v\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "diagnostics of cluster %q recorded in %s\n", f.Name, f.ArtifactsDir)
+		}
+	}
+	if f.retain {
+		fmt.Fprintf(os.Stderr, "cluster %q retained, delete it with `keink delete cluster --name %s`\n", f.Name, f.Name)
+		return nil
+	}
+
+	if f.KubeconfigPath != "" {
+		_ = os.Remove(f.KubeconfigPath)
+	}
+	if err := f.kind.Delete(f.Name, ""); err != nil {
+		return err
+	}
+	return f.Provider.DeleteContainers(f.Name)
+}
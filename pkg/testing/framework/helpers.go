@@ -0,0 +1,77 @@
+package framework
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubeedge/keink/pkg/cluster"
+)
+
+// WaitForEdgeNodeReady fails the test unless the edge node is Ready within
+// timeout
+func (f *Framework) WaitForEdgeNodeReady(t testing.TB, nodeName string, timeout time.Duration) {
+	t.Helper()
+	if err := f.Provider.WaitForEdgeNode(f.Name, nodeName, true, timeout); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// WaitForEdgeNodeNotReady fails the test unless the edge node is NotReady
+// within timeout
+func (f *Framework) WaitForEdgeNodeNotReady(t testing.TB, nodeName string, timeout time.Duration) {
+	t.Helper()
+	if err := f.Provider.WaitForEdgeNode(f.Name, nodeName, false, timeout); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// ExecOnEdge runs the command in the edge node container and returns its
+// combined output, the test fails when the command does
+func (f *Framework) ExecOnEdge(t testing.TB, nodeName, command string, args ...string) string {
+	t.Helper()
+	lines, err := f.Provider.ExecOnEdgeNode(f.Name, nodeName, command, args...)
+	if err != nil {
+		t.Fatalf("%v: %s", err, strings.Join(lines, "\n"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Kubectl runs kubectl on the control-plane node and returns its combined
+// output, the test fails when kubectl does
+func (f *Framework) Kubectl(t testing.TB, args ...string) string {
+	t.Helper()
+	lines, err := f.Provider.Kubectl(f.Name, args...)
+	if err != nil {
+		t.Fatalf("%v: %s", err, strings.Join(lines, "\n"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// PartitionEdge cuts the edge node off from cloudcore, the partition is
+// healed when the test ends
+func (f *Framework) PartitionEdge(t testing.TB, nodeName string) {
+	t.Helper()
+	if err := f.Provider.PartitionEdgeNode(f.Name, nodeName); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := f.Provider.HealEdgeNode(f.Name, nodeName); err != nil {
+			t.Errorf("failed to heal edge node %s: %v", nodeName, err)
+		}
+	})
+}
+
+// DegradeEdge degrades the link from the edge node to cloudcore, the link
+// is healed when the test ends
+func (f *Framework) DegradeEdge(t testing.TB, nodeName string, d cluster.NetworkDegradation) {
+	t.Helper()
+	if err := f.Provider.DegradeEdgeNode(f.Name, nodeName, d); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := f.Provider.HealEdgeNode(f.Name, nodeName); err != nil {
+			t.Errorf("failed to heal edge node %s: %v", nodeName, err)
+		}
+	})
+}